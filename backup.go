@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultBackupCount is the number of rotating .bak generations kept per list.
+// Backups rotate whenever the snapshot is rewritten, which since the journal
+// is on compaction: every journalCompactThreshold operations, on a merge and
+// on a restore. A backup thus lags the list by up to that many operations.
+const defaultBackupCount = 5
+
+// BackupInfo describes one backup generation of a list file
+type BackupInfo struct {
+	Path       string
+	Generation int
+	ModTime    time.Time
+	TodoCount  int
+}
+
+// backupPath returns the path of the given backup generation (1 = newest)
+func backupPath(filename string, generation int) string {
+	return fmt.Sprintf("%s.bak.%d", filename, generation)
+}
+
+// writeFileAtomic writes a file through a temp file in the same directory,
+// fsyncs it and renames it over the target, so a crash or full disk never
+// leaves a truncated file behind. When keepBackups > 0 the previous content
+// is rotated into .bak generations first.
+func writeFileAtomic(filename string, keepBackups int, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// Keep the permissions of the file we are replacing
+	mode := os.FileMode(0644)
+	if info, statErr := os.Stat(filename); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err = os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+
+	if keepBackups > 0 {
+		if err = rotateBackups(filename, keepBackups); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir fsyncs a directory so that a rename inside it is durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// Some filesystems don't support fsync on directories; that's not fatal
+	d.Sync()
+	return nil
+}
+
+// rotateBackups shifts existing backups one generation back and copies the
+// current file into generation 1
+func rotateBackups(filename string, keep int) error {
+	if _, err := os.Stat(filename); err != nil {
+		if os.IsNotExist(err) {
+			// Nothing to back up yet
+			return nil
+		}
+		return err
+	}
+
+	os.Remove(backupPath(filename, keep))
+	for gen := keep - 1; gen >= 1; gen-- {
+		from := backupPath(filename, gen)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, backupPath(filename, gen+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return copyFile(filename, backupPath(filename, 1))
+}
+
+// copyFile copies src to dst, flushing dst to disk
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// listBackupFiles returns the existing .bak generations of a file, newest
+// first, using count to report how many todos each one holds
+func listBackupFiles(filename string, keep int, count func(path string) int) []BackupInfo {
+	var backups []BackupInfo
+	for gen := 1; gen <= keep; gen++ {
+		path := backupPath(filename, gen)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, BackupInfo{
+			Path:       path,
+			Generation: gen,
+			ModTime:    info.ModTime(),
+			TodoCount:  count(path),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime.After(backups[j].ModTime)
+	})
+	return backups
+}
+
+// ListBackups returns the available backups of this list, newest first
+func (tl *TodoList) ListBackups() []BackupInfo {
+	return tl.store.ListBackups(tl.project)
+}
+
+// RestoreBackup replaces the todos of the list with the ones in the given
+// backup. The current content is first compacted, then rotated into the
+// backups, so a restore can be undone.
+func (tl *TodoList) RestoreBackup(path string) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	todos, err := tl.store.LoadBackup(tl.project, path)
+	if err != nil {
+		return fmt.Errorf("không thể đọc bản sao lưu: %v", err)
+	}
+
+	// The snapshot misses the journal entries until compacted
+	if tl.journal.Len() > 0 {
+		if err := tl.compact(); err != nil {
+			return err
+		}
+	}
+	tl.setTodos(todos)
+	return tl.compact()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreBackupKeepsJournal(t *testing.T) {
+	dir := t.TempDir()
+	store := NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project"))
+	list := NewTodoList(store, "")
+	for _, description := range []string{"một", "hai"} {
+		if err := list.AddTodo(description); err != nil {
+			t.Fatal(err)
+		}
+		if err := list.Compact(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only in the journal
+	if err := list.AddTodo("ba"); err != nil {
+		t.Fatal(err)
+	}
+
+	older := list.ListBackups()
+	if len(older) == 0 {
+		t.Fatal("no backup after compaction")
+	}
+	if err := list.RestoreBackup(older[0].Path); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := list.ListBackups()
+	if len(backups) == 0 || backups[0].TodoCount != 3 {
+		t.Fatalf("newest backup = %+v, want the 3 todos before the restore", backups)
+	}
+	if err := list.RestoreBackup(backups[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	if todos := list.GetTodos(); len(todos) != 3 {
+		t.Errorf("undone restore has %d todos, want 3", len(todos))
+	}
+}
@@ -0,0 +1,586 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// CSV and JSON files are read as a table: a CSV file has a header row, a
+// JSON file is an array of objects, or an object with a "todos" array such
+// as the list files of the JSON store, whose keys are the columns. The
+// columns are mapped to the fields of a todo in the import dialog; columns
+// named as exported, or like the aliases of a field, are mapped
+// automatically.
+const (
+	bulkSkipOption     = "(bỏ qua)"
+	bulkPreviewRows    = 5
+	utf8BOM            = "\ufeff"
+	bulkDateLayout     = "2006-01-02"
+	bulkDescriptionKey = "description"
+)
+
+// bulkField is a field of a todo as a column of a CSV or JSON file
+type bulkField struct {
+	Key     string   // Column name of exported files, the JSON name of the field
+	Label   string   // Shown in the import dialog
+	Aliases []string // Other column names mapped automatically, lowercase
+	get     func(Todo) string
+	set     func(todo *Todo, value string) error
+}
+
+// bulkFields are the importable fields, in the column order of exports
+var bulkFields = []bulkField{
+	{
+		Key: "id", Label: "ID", Aliases: []string{"#"},
+		get: func(t Todo) string { return strconv.Itoa(t.ID) },
+		set: func(t *Todo, v string) (err error) { t.ID, err = parseBulkID(v); return },
+	},
+	{
+		Key: bulkDescriptionKey, Label: "Mô tả", Aliases: []string{"mô tả", "công việc", "tên", "title", "summary", "name", "task", "content"},
+		get: func(t Todo) string { return t.Description },
+		set: func(t *Todo, v string) error { t.Description = strings.Join(strings.Fields(v), " "); return nil },
+	},
+	{
+		Key: "completed", Label: "Hoàn thành", Aliases: []string{"hoàn thành", "xong", "trạng thái", "done", "status"},
+		get: func(t Todo) string { return strconv.FormatBool(t.Completed) },
+		set: func(t *Todo, v string) (err error) { t.Completed, err = parseBulkBool(v); return },
+	},
+	{
+		Key: "priority", Label: "Độ ưu tiên", Aliases: []string{"độ ưu tiên", "ưu tiên"},
+		get: func(t Todo) string { return priorityNameOrEmpty(t.Priority) },
+		set: func(t *Todo, v string) (err error) { t.Priority, err = parseBulkPriority(v); return },
+	},
+	{
+		Key: "tags", Label: "Thẻ", Aliases: []string{"thẻ", "tag", "labels", "categories"},
+		get: func(t Todo) string { return strings.Join(t.Tags, ",") },
+		set: func(t *Todo, v string) error { t.Tags = parseBulkTags(v); return nil },
+	},
+	{
+		Key: "start_at", Label: "Bắt đầu", Aliases: []string{"bắt đầu", "start", "start date"},
+		get: func(t Todo) string { return formatBulkDate(t.StartAt) },
+		set: func(t *Todo, v string) (err error) { t.StartAt, err = parseBulkDay(v); return },
+	},
+	{
+		Key: "due_at", Label: "Hạn", Aliases: []string{"hạn", "hạn chót", "due", "due date", "deadline"},
+		get: func(t Todo) string { return formatBulkDate(t.DueAt) },
+		set: func(t *Todo, v string) (err error) { t.DueAt, err = parseBulkDay(v); return },
+	},
+	{
+		Key: "created_at", Label: "Ngày tạo", Aliases: []string{"ngày tạo", "created", "created date"},
+		get: func(t Todo) string { return formatBulkTime(t.CreatedAt) },
+		set: func(t *Todo, v string) (err error) { t.CreatedAt, err = parseBulkTime(v); return },
+	},
+	{
+		Key: "completed_at", Label: "Hoàn thành lúc", Aliases: []string{"ngày hoàn thành", "hoàn thành lúc", "completed date"},
+		get: func(t Todo) string { return formatBulkTime(t.CompletedAt) },
+		set: func(t *Todo, v string) (err error) { t.CompletedAt, err = parseBulkTime(v); return },
+	},
+	{
+		Key: "parent_id", Label: "ID cha", Aliases: []string{"id cha", "cha", "parent"},
+		get: func(t Todo) string { return idOrEmpty(t.ParentID) },
+		set: func(t *Todo, v string) (err error) { t.ParentID, err = parseBulkID(v); return },
+	},
+	{
+		Key: "recurrence", Label: "Lặp lại", Aliases: []string{"lặp lại", "repeat", "rrule"},
+		get: func(t Todo) string { return t.Recurrence.String() },
+		set: func(t *Todo, v string) (err error) {
+			if v == "" {
+				return nil
+			}
+			t.Recurrence, err = ParseRecurrence(v)
+			return err
+		},
+	},
+}
+
+// priorityNameOrEmpty returns the persisted name of a priority, "" for none
+func priorityNameOrEmpty(p Priority) string {
+	if p == PriorityNone {
+		return ""
+	}
+	return p.String()
+}
+
+// idOrEmpty returns an ID as text, "" for 0
+func idOrEmpty(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// formatBulkDate formats a day, "" if unset
+func formatBulkDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(bulkDateLayout)
+}
+
+// formatBulkTime formats an exact time, "" if unset
+func formatBulkTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// parseBulkID parses an ID; an empty cell is 0
+func parseBulkID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("ID không hợp lệ: %q", value)
+	}
+	return id, nil
+}
+
+// parseBulkBool parses a completed state as written by people or by other
+// apps; an empty cell is not completed
+func parseBulkBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "false", "0", "no", "n", "không", "chưa", "todo", "open", "needs-action":
+		return false, nil
+	case "true", "1", "yes", "y", "x", "có", "xong", "rồi", "✓", "✔", "✅", "done", "completed", "hoàn thành":
+		return true, nil
+	}
+	return false, fmt.Errorf("không hiểu trạng thái %q", value)
+}
+
+// parseBulkPriority parses a priority by name, label, todo.txt letter or
+// number from 0 (none) to 4 (urgent)
+func parseBulkPriority(value string) (Priority, error) {
+	if value == "" {
+		return PriorityNone, nil
+	}
+	if p, err := ParsePriority(value); err == nil {
+		return p, nil
+	}
+	for i, label := range priorityLabels {
+		if strings.EqualFold(value, label) {
+			return Priority(i), nil
+		}
+	}
+	if len(value) == 1 {
+		if p, ok := parseTodoTxtPriority(strings.ToUpper(value)[0]); ok {
+			return p, nil
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil && Priority(n).Valid() {
+		return Priority(n), nil
+	}
+	return PriorityNone, fmt.Errorf("độ ưu tiên không hợp lệ: %q", value)
+}
+
+// parseBulkTags splits tags separated by commas, semicolons or spaces,
+// with or without "#"
+func parseBulkTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	}) {
+		tags = addTag(tags, strings.TrimPrefix(tag, "#"))
+	}
+	return tags
+}
+
+// parseBulkTime parses a date, as in queries, or an exact time in RFC 3339
+// or "2006-01-02 15:04" form; an empty cell is unset
+func parseBulkTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "02/01/2006 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return parseQueryDate(value)
+}
+
+// parseBulkDay parses a start or due date, dropping the time of day
+func parseBulkDay(value string) (time.Time, error) {
+	t, err := parseBulkTime(value)
+	if err != nil || t.IsZero() {
+		return t, err
+	}
+	return startOfDay(t), nil
+}
+
+// bulkTable is the content of a CSV or JSON file
+type bulkTable struct {
+	Headers []string
+	Rows    [][]string
+	JSON    bool
+}
+
+// rowName names a row in error messages, the way a user finds it in the file
+func (t bulkTable) rowName(i int) string {
+	if t.JSON {
+		return fmt.Sprintf("mục %d", i+1)
+	}
+	return fmt.Sprintf("dòng %d", i+2)
+}
+
+// cell returns a cell of a row, "" if the row is shorter
+func (t bulkTable) cell(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
+
+// csvDelimiter guesses the delimiter of a CSV file from its header: Excel
+// writes ";" in locales where "," is the decimal separator
+func csvDelimiter(header string) rune {
+	delimiter, count := ',', strings.Count(header, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(header, string(d)); n > count {
+			delimiter, count = d, n
+		}
+	}
+	return delimiter
+}
+
+// ReadCSVTable reads a CSV file whose first row names the columns
+func ReadCSVTable(r io.Reader) (bulkTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return bulkTable{}, err
+	}
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = csvDelimiter(string(header))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return bulkTable{}, fmt.Errorf("file CSV không hợp lệ: %v", err)
+	}
+	if len(records) == 0 {
+		return bulkTable{}, fmt.Errorf("file CSV trống")
+	}
+	return bulkTable{Headers: records[0], Rows: records[1:]}, nil
+}
+
+// jsonCell returns a JSON value as the text of a cell. Arrays, such as
+// tags, are joined with commas.
+func jsonCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		cells := make([]string, len(v))
+		for i, item := range v {
+			cells[i] = jsonCell(item)
+		}
+		return strings.Join(cells, ",")
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// ReadJSONTable reads a JSON array of objects, or an object holding one as
+// "todos". The columns are the keys of the objects, the exported ones
+// first.
+func ReadJSONTable(r io.Reader) (bulkTable, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var root any
+	if err := decoder.Decode(&root); err != nil {
+		return bulkTable{}, fmt.Errorf("file JSON không hợp lệ: %v", err)
+	}
+	if object, ok := root.(map[string]any); ok {
+		root = object["todos"]
+	}
+	items, ok := root.([]any)
+	if !ok {
+		return bulkTable{}, fmt.Errorf("file JSON phải là một mảng công việc")
+	}
+
+	order := make(map[string]int, len(bulkFields))
+	for i, field := range bulkFields {
+		order[field.Key] = i
+	}
+	seen := make(map[string]bool)
+	table := bulkTable{JSON: true}
+	for _, item := range items {
+		object, _ := item.(map[string]any)
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				table.Headers = append(table.Headers, key)
+			}
+		}
+	}
+	sort.Slice(table.Headers, func(i, j int) bool {
+		a, aKnown := order[table.Headers[i]]
+		b, bKnown := order[table.Headers[j]]
+		if aKnown != bKnown {
+			return aKnown
+		}
+		if aKnown {
+			return a < b
+		}
+		return table.Headers[i] < table.Headers[j]
+	})
+
+	for _, item := range items {
+		object, _ := item.(map[string]any)
+		row := make([]string, len(table.Headers))
+		for i, key := range table.Headers {
+			row[i] = jsonCell(object[key])
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// bulkMapping maps the keys of bulkFields to columns; -1 or a missing key
+// skips the field
+type bulkMapping map[string]int
+
+// guessBulkMapping maps the columns named like a field to it
+func guessBulkMapping(headers []string) bulkMapping {
+	mapping := make(bulkMapping)
+	for _, field := range bulkFields {
+		for column, header := range headers {
+			name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, utf8BOM)))
+			if _, mapped := mapping[field.Key]; !mapped && (name == field.Key || slices.Contains(field.Aliases, name)) {
+				mapping[field.Key] = column
+			}
+		}
+	}
+	return mapping
+}
+
+// column returns the column mapped to a field, -1 if none
+func (m bulkMapping) column(key string) int {
+	if column, ok := m[key]; ok {
+		return column
+	}
+	return -1
+}
+
+// todo converts a row with the mapping
+func (t bulkTable) todo(row []string, mapping bulkMapping) (Todo, error) {
+	var todo Todo
+	for _, field := range bulkFields {
+		column := mapping.column(field.Key)
+		if column < 0 {
+			continue
+		}
+		if err := field.set(&todo, t.cell(row, column)); err != nil {
+			return Todo{}, fmt.Errorf("%s: %v", field.Label, err)
+		}
+	}
+	if todo.Description == "" {
+		return Todo{}, fmt.Errorf("thiếu mô tả công việc")
+	}
+	if !todo.Completed {
+		todo.CompletedAt = time.Time{}
+	}
+	return todo, nil
+}
+
+// descriptionKey returns the form of a description compared when
+// skipping duplicates
+func descriptionKey(description string) string {
+	return strings.ToLower(strings.Join(strings.Fields(description), " "))
+}
+
+// Todos converts the rows with the mapping. Rows that cannot be converted
+// are reported and skipped, as are, if existing is not nil, the rows whose
+// description is in existing or in an earlier row.
+func (t bulkTable) Todos(mapping bulkMapping, existing map[string]bool) ([]Todo, []error) {
+	var todos []Todo
+	var errs []error
+	for i, row := range t.Rows {
+		todo, err := t.todo(row, mapping)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", t.rowName(i), err))
+			continue
+		}
+		if existing != nil {
+			key := descriptionKey(todo.Description)
+			if existing[key] {
+				errs = append(errs, fmt.Errorf("%s: trùng mô tả %q", t.rowName(i), todo.Description))
+				continue
+			}
+			existing[key] = true
+		}
+		todo.Position = len(todos) + 1
+		todos = append(todos, todo)
+	}
+	return todos, errs
+}
+
+// WriteCSV writes todos as a CSV file with a header row. The file starts
+// with a byte order mark, so that Excel reads it as UTF-8.
+func WriteCSV(w io.Writer, todos []Todo) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	record := make([]string, len(bulkFields))
+	for i, field := range bulkFields {
+		record[i] = field.Key
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		for i, field := range bulkFields {
+			record[i] = field.get(todo)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes todos as a JSON array, in the form of the JSON store
+func WriteJSON(w io.Writer, todos []Todo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(todos)
+}
+
+// exportBulk saves the list, without the trash, as a CSV or JSON file
+func (app *TodoApp) exportBulk(isProject, asJSON bool) {
+	todos := app.listFor(app.projectKey(isProject)).GetTodos()
+	if asJSON {
+		app.exportFile(app.transferFileName(isProject, ".json"), func(w io.Writer) error {
+			return WriteJSON(w, todos)
+		})
+		return
+	}
+	app.exportFile(app.transferFileName(isProject, ".csv"), func(w io.Writer) error {
+		return WriteCSV(w, todos)
+	})
+}
+
+// importBulk reads a CSV or JSON file and shows its column mapping
+func (app *TodoApp) importBulk(isProject, asJSON bool) {
+	project := app.projectKey(isProject)
+	app.importFile(func(r io.Reader) {
+		read := ReadCSVTable
+		if asJSON {
+			read = ReadJSONTable
+		}
+		table, err := read(r)
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		if len(table.Rows) == 0 {
+			dialog.ShowError(fmt.Errorf("không có công việc nào để nhập"), app.window)
+			return
+		}
+		app.showBulkImportDialog(project, table)
+	})
+}
+
+// showBulkImportDialog lets the user map the columns of a file to the
+// fields of a todo, previewing the first rows, before importing them
+func (app *TodoApp) showBulkImportDialog(project string, table bulkTable) {
+	columns := []string{bulkSkipOption}
+	for i, header := range table.Headers {
+		columns = append(columns, fmt.Sprintf("%d. %s", i+1, header))
+	}
+	mapping := guessBulkMapping(table.Headers)
+
+	preview := container.NewVBox()
+	updatePreview := func() {
+		preview.RemoveAll()
+		for i, row := range table.Rows[:min(bulkPreviewRows, len(table.Rows))] {
+			var text string
+			if todo, err := table.todo(row, mapping); err != nil {
+				text = fmt.Sprintf("⚠️ %s: %v", table.rowName(i), err)
+			} else {
+				text = conflictVersionText(&todo)
+			}
+			label := widget.NewLabel(text)
+			label.Truncation = fyne.TextTruncateEllipsis
+			preview.Add(label)
+		}
+	}
+
+	form := widget.NewForm()
+	for _, field := range bulkFields {
+		key := field.Key
+		selectColumn := widget.NewSelect(columns, func(selected string) {
+			mapping[key] = -1
+			for i, column := range columns {
+				if column == selected {
+					mapping[key] = i - 1
+				}
+			}
+			updatePreview()
+		})
+		selectColumn.SetSelected(columns[mapping.column(key)+1])
+		form.Append(field.Label+":", selectColumn)
+	}
+	updatePreview()
+
+	dedupe := widget.NewCheck("Bỏ qua công việc trùng mô tả với công việc có sẵn", nil)
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("%d dòng, %d cột. Chọn cột cho mỗi trường:", len(table.Rows), len(table.Headers))),
+		form,
+		widget.NewSeparator(),
+		widget.NewLabel(fmt.Sprintf("Xem trước %d dòng đầu:", min(bulkPreviewRows, len(table.Rows)))),
+		preview,
+		widget.NewSeparator(),
+		dedupe,
+	)
+
+	d := dialog.NewCustomConfirm("📥 Nhập CSV / JSON", "Tiếp tục", "Hủy", container.NewVScroll(content), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if mapping.column(bulkDescriptionKey) < 0 {
+			dialog.ShowError(fmt.Errorf("chọn cột cho trường Mô tả"), app.window)
+			return
+		}
+
+		var existing map[string]bool
+		if dedupe.Checked {
+			existing = make(map[string]bool)
+			for _, todo := range app.listFor(project).GetTodos() {
+				existing[descriptionKey(todo.Description)] = true
+			}
+		}
+		todos, errs := table.Todos(mapping, existing)
+		app.confirmImport(project, todos, errs)
+	}, app.window)
+	d.Resize(fyne.NewSize(650, 600))
+	d.Show()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBulkRoundTrip(t *testing.T) {
+	created := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2025, 10, d, 0, 0, 0, 0, time.Local)
+	}
+	rule, _ := ParseRecurrence("FREQ=MONTHLY;BYMONTHDAY=1,15")
+	todos := []Todo{
+		{ID: 1, Description: `Mua sữa, "loại tốt"`, CreatedAt: created, Position: 1},
+		{ID: 2, Description: "Sửa xe", CreatedAt: created, StartAt: day(3), DueAt: day(5), Priority: PriorityHigh,
+			Tags: []string{"nhà", "xe"}, ParentID: 1, Recurrence: rule, Position: 2},
+		{ID: 4, Description: "Xong rồi", CreatedAt: created, Completed: true, CompletedAt: created.Add(time.Hour),
+			Priority: PriorityUrgent, Position: 3},
+	}
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer, []Todo) error
+		read  func(*bytes.Buffer) (bulkTable, error)
+	}{
+		{"CSV",
+			func(buf *bytes.Buffer, todos []Todo) error { return WriteCSV(buf, todos) },
+			func(buf *bytes.Buffer) (bulkTable, error) { return ReadCSVTable(buf) }},
+		{"JSON",
+			func(buf *bytes.Buffer, todos []Todo) error { return WriteJSON(buf, todos) },
+			func(buf *bytes.Buffer) (bulkTable, error) { return ReadJSONTable(buf) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.write(&buf, todos); err != nil {
+				t.Fatal(err)
+			}
+			table, err := test.read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mapping := guessBulkMapping(table.Headers)
+			for _, field := range bulkFields {
+				if mapping.column(field.Key) < 0 {
+					t.Errorf("column %q is not mapped in %q", field.Key, table.Headers)
+				}
+			}
+			got, errs := table.Todos(mapping, nil)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if !sameTodos(got, todos) {
+				t.Errorf("read %+v, want %+v", got, todos)
+			}
+		})
+	}
+}
+
+func TestWriteCSVBOM(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), utf8BOM+"id,description,") {
+		t.Errorf("CSV file starts with %q", buf.String())
+	}
+}
+
+func TestReadCSVTable(t *testing.T) {
+	tests := []struct {
+		name, text string
+		headers    []string
+		rows       [][]string
+	}{
+		{"comma", "Tên,Hạn\nMua sữa,05/10/2025\n", []string{"Tên", "Hạn"}, [][]string{{"Mua sữa", "05/10/2025"}}},
+		{"Excel semicolon", utf8BOM + "Tên;Điểm\r\n\"Đọc sách; báo\";1,5\r\n", []string{"Tên", "Điểm"}, [][]string{{"Đọc sách; báo", "1,5"}}},
+		{"tab", "Tên\tThẻ\nA\tx,y\n", []string{"Tên", "Thẻ"}, [][]string{{"A", "x,y"}}},
+		{"short row", "a,b,c\n1\n", []string{"a", "b", "c"}, [][]string{{"1"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, err := ReadCSVTable(strings.NewReader(test.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(table.Headers, test.headers) || !slices.EqualFunc(table.Rows, test.rows, slices.Equal) {
+				t.Errorf("table = %q %q, want %q %q", table.Headers, table.Rows, test.headers, test.rows)
+			}
+		})
+	}
+
+	if _, err := ReadCSVTable(strings.NewReader("")); err == nil {
+		t.Error("empty CSV file read")
+	}
+}
+
+func TestReadJSONTable(t *testing.T) {
+	text := `{"todos": [
+		{"title": "Một", "done": true, "tags": ["a", "b"], "id": 3},
+		{"title": "Hai", "extra": {"x": 1}, "due": null},
+		"không phải đối tượng"
+	]}`
+	table, err := ReadJSONTable(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Known fields first, in export order, then the others by name
+	wantHeaders := []string{"id", "tags", "done", "due", "extra", "title"}
+	wantRows := [][]string{
+		{"3", "a,b", "true", "", "", "Một"},
+		{"", "", "", "", `{"x":1}`, "Hai"},
+		{"", "", "", "", "", ""},
+	}
+	if !slices.Equal(table.Headers, wantHeaders) || !slices.EqualFunc(table.Rows, wantRows, slices.Equal) {
+		t.Errorf("table = %q %q, want %q %q", table.Headers, table.Rows, wantHeaders, wantRows)
+	}
+
+	for _, text := range []string{`{"todos": 1}`, `"a"`, `[`} {
+		if _, err := ReadJSONTable(strings.NewReader(text)); err == nil {
+			t.Errorf("ReadJSONTable(%q) succeeded", text)
+		}
+	}
+}
+
+func TestGuessBulkMapping(t *testing.T) {
+	mapping := guessBulkMapping([]string{utf8BOM + "Công việc", "Xong", "Ưu tiên", "Deadline", "Ghi chú", "Title"})
+	want := map[string]int{bulkDescriptionKey: 0, "completed": 1, "priority": 2, "due_at": 3}
+	for _, field := range bulkFields {
+		column, ok := want[field.Key]
+		if !ok {
+			column = -1
+		}
+		if got := mapping.column(field.Key); got != column {
+			t.Errorf("field %q is mapped to column %d, want %d", field.Key, got, column)
+		}
+	}
+}
+
+func TestBulkTableTodos(t *testing.T) {
+	table := bulkTable{
+		Headers: []string{"Tên", "Xong", "Ưu tiên", "Thẻ", "Hạn", "Hoàn thành lúc"},
+		Rows: [][]string{
+			{" Mua   sữa ", "x", "B", "#nhà; Chợ", "05/10/2025", "2025-10-02 18:00"},
+			{"Đọc sách", "không", "3", "", "2025-10-05T22:00:00+07:00", "2025-10-02 18:00"},
+			{"mua sữa", "", "", "", "", ""},
+			{"", "", "", "", "", ""},
+			{"Sai", "có lẽ", "", "", "", ""},
+			{"Sai ưu tiên", "", "cao nhất", "", "", ""},
+		},
+	}
+	mapping := guessBulkMapping(table.Headers)
+
+	todos, errs := table.Todos(mapping, map[string]bool{})
+	want := []Todo{
+		{Description: "Mua sữa", Completed: true, Priority: PriorityHigh, Tags: []string{"nhà", "chợ"},
+			DueAt: time.Date(2025, 10, 5, 0, 0, 0, 0, time.Local), CompletedAt: time.Date(2025, 10, 2, 18, 0, 0, 0, time.Local), Position: 1},
+		{Description: "Đọc sách", Priority: PriorityHigh,
+			DueAt: startOfDay(time.Date(2025, 10, 5, 22, 0, 0, 0, time.FixedZone("", 7*3600))), Position: 2},
+	}
+	if !sameTodos(todos, want) {
+		t.Errorf("todos = %+v, want %+v", todos, want)
+	}
+
+	wantErrors := []string{"dòng 4: trùng mô tả", "dòng 5: thiếu mô tả", "dòng 6: Hoàn thành:", "dòng 7: Độ ưu tiên:"}
+	if len(errs) != len(wantErrors) {
+		t.Fatalf("errors = %v, want %d", errs, len(wantErrors))
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), wantErrors[i]) {
+			t.Errorf("error %d = %q, want %q…", i, err, wantErrors[i])
+		}
+	}
+
+	// Without dedupe, the same description is imported twice
+	if todos, _ := table.Todos(mapping, nil); len(todos) != 3 {
+		t.Errorf("without dedupe: %+v", todos)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Completion event kinds
+const (
+	EventCompleted = "completed"
+	EventReopened  = "reopened"
+)
+
+// CompletionEvent records when a todo was completed or reopened
+type CompletionEvent struct {
+	Event string    `json:"event"`
+	At    time.Time `json:"at"`
+}
+
+// String formats the event as "completed@2006-01-02T15:04:05Z07:00"
+func (e CompletionEvent) String() string {
+	return e.Event + "@" + e.At.Format(time.RFC3339)
+}
+
+// Label returns the Vietnamese label of the event
+func (e CompletionEvent) Label() string {
+	if e.Event == EventReopened {
+		return "↩️ Mở lại"
+	}
+	return "✅ Hoàn thành"
+}
+
+// ParseCompletionEvent parses an event written by String
+func ParseCompletionEvent(s string) (CompletionEvent, error) {
+	event, at, ok := strings.Cut(s, "@")
+	if !ok || (event != EventCompleted && event != EventReopened) {
+		return CompletionEvent{}, fmt.Errorf("sự kiện hoàn thành không hợp lệ: %q", s)
+	}
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return CompletionEvent{}, fmt.Errorf("sự kiện hoàn thành không hợp lệ: %q", s)
+	}
+	return CompletionEvent{Event: event, At: t}, nil
+}
+
+// setCompleted sets the completion state of the todo at index i and adds
+// the matching event to its completion history
+func (tl *TodoList) setCompleted(i int, completed bool, at time.Time) {
+	todo := &tl.todos[i]
+	todo.Completed = completed
+	if completed {
+		todo.CompletedAt = at
+		todo.Completions = append(todo.Completions, CompletionEvent{Event: EventCompleted, At: at})
+	} else {
+		todo.CompletedAt = time.Time{}
+		todo.Completions = append(todo.Completions, CompletionEvent{Event: EventReopened, At: at})
+	}
+}
+
+// MarkIncomplete reopens a completed todo. The next occurrence already
+// created for a repeating todo is kept.
+func (tl *TodoList) MarkIncomplete(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+	if !tl.todos[i].Completed {
+		return nil
+	}
+
+	tl.setCompleted(i, false, time.Now())
+	todo := tl.todos[i]
+	return tl.record(OpReopen, id, &todo)
+}
+
+// CompletionHistory returns the completed and reopened events of a todo,
+// oldest first
+func (tl *TodoList) CompletionHistory(id int) []CompletionEvent {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	for _, todo := range tl.todos {
+		if todo.ID == id {
+			return todo.Completions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configFileName is the file of the application settings in the data root
+const configFileName = "config.json"
+
+// Config holds the persisted application settings
+type Config struct {
+	StorageBackend string `json:"storage_backend"`  // text, json or embedded
+	TrashPurgeDays int    `json:"trash_purge_days"` // Auto-purge age of trashed todos; 0 = never
+	ShowLunarDates bool   `json:"show_lunar_dates"` // Show lunar (âm lịch) dates on the cards
+
+	SmartLists []SmartList `json:"smart_lists,omitempty"` // Saved searches shown as extra sub-tabs
+
+	path string // File the settings are loaded from and saved to
+}
+
+// SmartList is a named search query shown as an extra sub-tab of the lists
+type SmartList struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// defaultConfig returns the settings used when no config file exists
+func defaultConfig() *Config {
+	return &Config{
+		StorageBackend: BackendText,
+		TrashPurgeDays: 30,
+	}
+}
+
+// TrashPurgeAge returns how long todos stay in the trash before being
+// purged; zero means they are kept forever
+func (c *Config) TrashPurgeAge() time.Duration {
+	return time.Duration(c.TrashPurgeDays) * 24 * time.Hour
+}
+
+// SetSmartList adds a smart list, or replaces the smart list named old when
+// old is not "". The name must be unique and the query must parse.
+func (c *Config) SetSmartList(old string, list SmartList) error {
+	list.Name = strings.TrimSpace(list.Name)
+	list.Query = strings.TrimSpace(list.Query)
+	if list.Name == "" {
+		return fmt.Errorf("tên danh sách không được để trống")
+	}
+	if _, err := ParseQuery(list.Query); err != nil {
+		return fmt.Errorf("truy vấn không hợp lệ: %v", err)
+	}
+
+	index := -1
+	for i, existing := range c.SmartLists {
+		if old != "" && existing.Name == old {
+			index = i
+		} else if strings.EqualFold(existing.Name, list.Name) {
+			return fmt.Errorf("danh sách %q đã tồn tại", list.Name)
+		}
+	}
+
+	if old == "" {
+		c.SmartLists = append(c.SmartLists, list)
+		return nil
+	}
+	if index < 0 {
+		return fmt.Errorf("không tìm thấy danh sách %q", old)
+	}
+	c.SmartLists[index] = list
+	return nil
+}
+
+// RemoveSmartList removes the smart list with the given name
+func (c *Config) RemoveSmartList(name string) {
+	for i, list := range c.SmartLists {
+		if list.Name == name {
+			c.SmartLists = append(c.SmartLists[:i], c.SmartLists[i+1:]...)
+			return
+		}
+	}
+}
+
+// LoadConfig loads the settings of a data root, falling back to defaults
+// when the file is missing or unreadable
+func LoadConfig(dataDir string) *Config {
+	path := filepath.Join(dataDir, configFileName)
+	cfg := defaultConfig()
+	cfg.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		fmt.Printf("⚠️ Không đọc được cấu hình %s: %v\n", path, err)
+		cfg = defaultConfig()
+		cfg.path = path
+	}
+	return cfg
+}
+
+// Save writes the settings to the config file
+func (c *Config) Save() error {
+	return writeFileAtomic(c.path, 0, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c)
+	})
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The data root holds every file of the app:
+//
+//	config.json            settings
+//	todos.txt, project/    text backend
+//	json/                  JSON backend
+//	todoapp.db             embedded backend
+//	themes/images/         background images of projects
+//
+// It is the --data-dir flag, else $TODOAPP_DATA, else $XDG_DATA_HOME/todoapp
+// (~/.local/share/todoapp when unset).
+const (
+	dataDirEnv     = "TODOAPP_DATA"
+	dataDirName    = "todoapp"
+	themeImagesDir = "themes/images" // Relative to the data root
+
+	// legacyDataDir is the directory of the settings, projects and images
+	// before the data root, relative to the working directory
+	legacyDataDir = "data"
+)
+
+// legacyDataFiles are the patterns of the files kept in the working
+// directory before the data root. A file moves to its path without the
+// legacyDataDir prefix. They only move along with a list file of the old
+// version, see hasLegacyData.
+var legacyDataFiles = []string{
+	"todos.txt*", // With its journal, backups and lock
+	"data/config.json",
+	"data/project",
+	"data/json",
+	"data/todoapp.db*",
+	"data/themes",
+}
+
+// resolveDataDir returns the absolute data root chosen by the flag, the
+// environment or the XDG base directory specification, and creates it
+func resolveDataDir(flagValue string) (string, error) {
+	dir := flagValue
+	if dir == "" {
+		dir = os.Getenv(dataDirEnv)
+	}
+	if dir == "" {
+		// Relative values are invalid per the specification and ignored
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if !filepath.IsAbs(dataHome) {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("không xác định được thư mục dữ liệu: %v", err)
+			}
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		dir = filepath.Join(dataHome, dataDirName)
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("không thể tạo thư mục dữ liệu %s: %v", dir, err)
+	}
+	return dir, nil
+}
+
+// dataFile resolves a path stored in the data, such as a background image:
+// relative paths are inside the data root, and paths under the legacy
+// "data/" directory moved there with it
+func (app *TodoApp) dataFile(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	path = filepath.Clean(path)
+	path = strings.TrimPrefix(path, legacyDataDir+string(filepath.Separator))
+	return filepath.Join(app.dataDir, path)
+}
+
+// migrateLegacyData moves the files an older version kept next to the
+// executable, or in the working directory, into a data root that holds no
+// data yet. A directory only counts when it holds a list file of the old
+// version, so that an unrelated data/ directory, e.g. in $HOME, stays where
+// it is. It returns the directory the files came from, "" if none.
+func migrateLegacyData(root string) (string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) > 0 {
+		return "", nil
+	}
+
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+	if wd, err := os.Getwd(); err == nil && !slices.Contains(dirs, wd) {
+		dirs = append(dirs, wd)
+	}
+
+	for _, dir := range dirs {
+		if !hasLegacyData(dir) {
+			continue
+		}
+		var files []string
+		for _, pattern := range legacyDataFiles {
+			matches, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+			files = append(files, matches...)
+		}
+
+		var errs []error
+		for _, file := range files {
+			rel, _ := filepath.Rel(dir, file)
+			rel = strings.TrimPrefix(rel, legacyDataDir+string(filepath.Separator))
+			target := filepath.Join(root, rel)
+			if err := movePath(file, target); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Printf("📦 %s → %s\n", file, target)
+		}
+		// Only removed once empty
+		os.Remove(filepath.Join(dir, legacyDataDir))
+		return dir, errors.Join(errs...)
+	}
+	return "", nil
+}
+
+// hasLegacyData reports whether dir holds the data of an older version: a
+// todos.txt list file, or a project file with its "# Project:" header in
+// data/project
+func hasLegacyData(dir string) bool {
+	if isListFile(filepath.Join(dir, "todos.txt"), "") {
+		return true
+	}
+	projects, _ := filepath.Glob(filepath.Join(dir, legacyDataDir, "project", "*.txt"))
+	for _, project := range projects {
+		if isListFile(project, headerProject) {
+			return true
+		}
+	}
+	return false
+}
+
+// isListFile reports whether a file is a list file in the v1 or v2 format:
+// it has a format header or records, every record can be read, and its
+// header holds a line starting with header unless header is ""
+func isListFile(path, header string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	lines, err := readLines(file)
+	if err != nil {
+		return false
+	}
+
+	version, found, hasHeader := formatV1, false, header == ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			if v, ok := parseFormatHeader(trimmed); ok {
+				version, found = v, true
+			}
+			if header != "" && strings.HasPrefix(line, header) {
+				hasHeader = true
+			}
+		default:
+			record := trimmed
+			if version >= formatV2 {
+				record = line
+			}
+			if _, err := decodeTodo(record, version); err != nil {
+				return false
+			}
+			found = true
+		}
+	}
+	return found && hasHeader
+}
+
+// movePath moves a file or directory, copying it when a rename is not
+// possible, e.g. across file systems. An existing destination is kept.
+func movePath(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s đã tồn tại, giữ nguyên %s", dst, src)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	if err := copyPath(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("không thể chuyển %s: %v", src, err)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath copies a file or, recursively, a directory
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
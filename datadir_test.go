@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files with their content under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigrateLegacyData(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		migrate bool
+	}{
+		{"unrelated data directory", map[string]string{
+			"data/json/todos.json": `{"a": 1}`,
+			"data/config.json":     `{}`,
+			"data/themes/x.png":    "png",
+		}, false},
+		{"unrelated todos.txt", map[string]string{
+			"todos.txt":            "- [ ] mua sữa\n",
+			"data/json/todos.json": `{"a": 1}`,
+		}, false},
+		{"unrelated project files", map[string]string{
+			"data/project/ghi-chu.txt": "Ghi chú\n",
+		}, false},
+		{"v1 list", map[string]string{
+			"todos.txt":        "1|Mua sữa|false|2025-10-01T08:30:00Z\n",
+			"data/config.json": `{}`,
+		}, true},
+		{"v2 list", map[string]string{
+			"todos.txt": formatHeaderPrefix + "2\n",
+		}, true},
+		{"project only", map[string]string{
+			"data/project/wee.txt": formatHeaderPrefix + "2\n" + headerProject + "wee\n\n1|Việc|false|2025-10-01T08:30:00Z\n",
+			"data/json/todos.json": `[]`,
+		}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wd, root := t.TempDir(), t.TempDir()
+			writeFiles(t, wd, test.files)
+			t.Chdir(wd)
+
+			from, err := migrateLegacyData(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.migrate != (from != "") {
+				t.Fatalf("migrateLegacyData moved from %q, want migrate %v", from, test.migrate)
+			}
+
+			for name := range test.files {
+				rel := filepath.FromSlash(name)
+				if moved, err := filepath.Rel(legacyDataDir, rel); err == nil && filepath.IsLocal(moved) {
+					rel = moved
+				}
+				_, errOld := os.Stat(filepath.Join(wd, filepath.FromSlash(name)))
+				_, errNew := os.Stat(filepath.Join(root, rel))
+				if test.migrate && (errOld == nil || errNew != nil) {
+					t.Errorf("%s was not moved", name)
+				}
+				if !test.migrate && (errOld != nil || errNew == nil) {
+					t.Errorf("%s was moved", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DueStatus classifies a todo by its due date relative to today
+type DueStatus int
+
+const (
+	DueNone     DueStatus = iota // No due date, or already completed
+	DueOverdue                   // Due before today
+	DueToday                     // Due today
+	DueUpcoming                  // Due after today
+)
+
+// startOfDay returns midnight of the day t falls on, in local time
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// sameDay reports whether two times fall on the same local day
+func sameDay(a, b time.Time) bool {
+	return startOfDay(a).Equal(startOfDay(b))
+}
+
+// DueStatusAt returns the due status of the todo as seen at now
+func (t Todo) DueStatusAt(now time.Time) DueStatus {
+	if t.DueAt.IsZero() || t.Completed {
+		return DueNone
+	}
+
+	today := startOfDay(now)
+	due := startOfDay(t.DueAt)
+	switch {
+	case due.Before(today):
+		return DueOverdue
+	case due.Equal(today):
+		return DueToday
+	default:
+		return DueUpcoming
+	}
+}
+
+// SetDates sets or clears (with a zero time) the start and due dates of a
+// todo. Dates are stored as local midnight of the chosen day.
+func (tl *TodoList) SetDates(id int, startAt, dueAt time.Time) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	startAt, dueAt, err := normalizeDates(startAt, dueAt)
+	if err != nil {
+		return err
+	}
+
+	tl.todos[i].StartAt = startAt
+	tl.todos[i].DueAt = dueAt
+	todo := tl.todos[i]
+	return tl.record(OpEdit, id, &todo)
+}
+
+// normalizeDates moves the start and due dates to local midnight and checks
+// that the start is not after the due date
+func normalizeDates(startAt, dueAt time.Time) (time.Time, time.Time, error) {
+	if !startAt.IsZero() {
+		startAt = startOfDay(startAt)
+	}
+	if !dueAt.IsZero() {
+		dueAt = startOfDay(dueAt)
+	}
+	if !startAt.IsZero() && !dueAt.IsZero() && startAt.After(dueAt) {
+		return startAt, dueAt, fmt.Errorf("ngày bắt đầu phải trước hoặc bằng hạn chót")
+	}
+	return startAt, dueAt, nil
+}
+
+// GetTodayTodos returns incomplete todos that are due today or overdue, or
+// that start today, ordered by due date
+func (tl *TodoList) GetTodayTodos() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	now := time.Now()
+	var today []Todo
+	for _, todo := range tl.activeTodos() {
+		status := todo.DueStatusAt(now)
+		startsToday := !todo.StartAt.IsZero() && sameDay(todo.StartAt, now)
+		if status == DueOverdue || status == DueToday || startsToday {
+			today = append(today, todo)
+		}
+	}
+	sortByDue(today)
+	return today
+}
+
+// GetUpcomingTodos returns incomplete todos due after today, ordered by due
+// date
+func (tl *TodoList) GetUpcomingTodos() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	now := time.Now()
+	var upcoming []Todo
+	for _, todo := range tl.activeTodos() {
+		if todo.DueStatusAt(now) == DueUpcoming {
+			upcoming = append(upcoming, todo)
+		}
+	}
+	sortByDue(upcoming)
+	return upcoming
+}
+
+// sortByDue orders todos by due date; todos without one come last
+func sortByDue(todos []Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, b := todos[i].DueAt, todos[j].DueAt
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.Before(b)
+	})
+}
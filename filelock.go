@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockTimeout is how long a writer waits for another process to release
+// the lock of a data file before giving up
+const lockTimeout = 5 * time.Second
+
+// ErrLocked is returned when another process keeps a data file locked for
+// longer than lockTimeout
+var ErrLocked = errors.New("dữ liệu đang được một cửa sổ khác của ứng dụng ghi, vui lòng thử lại")
+
+// fileLock is an exclusive advisory lock on the ".lock" file next to a
+// data file, shared by every process using the same data. The lock file
+// also holds a generation counter that writers increment, so that a
+// process can tell whether another one wrote the data since it read it.
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile locks path+".lock", waiting up to lockTimeout while another
+// process holds it. The lock is per open file, so a process must not lock
+// the same path twice.
+func lockFile(path string) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("không thể khóa %s: %v", path, err)
+		}
+		if locked {
+			return &fileLock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w (%s)", ErrLocked, path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock
+func (l *fileLock) Unlock() error {
+	unlockFile(l.file)
+	return l.file.Close()
+}
+
+// Generation returns the generation counter stored in the lock file; a new
+// lock file is generation 0
+func (l *fileLock) Generation() (int64, error) {
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(l.file)
+	if err != nil {
+		return 0, err
+	}
+
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return 0, nil
+	}
+	gen, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("file khóa %s không hợp lệ: %q", l.file.Name(), text)
+	}
+	return gen, nil
+}
+
+// SetGeneration stores a new generation counter in the lock file
+func (l *fileLock) SetGeneration(gen int64) error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := l.file.WriteAt([]byte(strconv.FormatInt(gen, 10)+"\n"), 0)
+	return err
+}
+
+// withFileLock runs fn while holding the lock of path
+func withFileLock(path string, fn func() error) error {
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return fn()
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File format versions understood by TodoList
+//
+// Version 1 (legacy): ID|Description|Completed|CreatedAt with no escaping.
+// Version 2: same fields, but every field is escaped so that "|", "\" and
+// line breaks inside a description can no longer corrupt the record. A v2
+// file starts its header with "# Format: 2". Optional fields follow the four
+// base fields as key=value pairs; unknown keys are ignored when reading.
+const (
+	formatV1      = 1
+	formatV2      = 2
+	currentFormat = formatV2
+
+	formatHeaderPrefix = "# Format: "
+	fieldSeparator     = '|'
+)
+
+// Keys of the optional key=value fields of a v2 record
+const (
+	fieldDeletedAt = "deleted"
+	fieldStartAt   = "start"
+	fieldDueAt     = "due"
+	fieldPriority  = "priority"
+	fieldTags      = "tags"
+	fieldParentID  = "parent"
+	fieldRepeat    = "repeat"
+	fieldCompleted = "done"
+	fieldHistory   = "history"
+	fieldPosition  = "pos"
+)
+
+// escapeField escapes a single record field for the v2 format
+func escapeField(s string) string {
+	if !strings.ContainsAny(s, "\\|\n\r") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '|':
+			b.WriteString(`\|`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// splitEscapedRecord splits a v2 record line on unescaped separators and
+// unescapes every field
+func splitEscapedRecord(line string) ([]string, error) {
+	var fields []string
+	var b strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(line) {
+				return nil, fmt.Errorf("ký tự thoát không hợp lệ ở cuối dòng")
+			}
+			i++
+			switch line[i] {
+			case '\\':
+				b.WriteByte('\\')
+			case '|':
+				b.WriteByte('|')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				return nil, fmt.Errorf("chuỗi thoát không hợp lệ: \\%c", line[i])
+			}
+		case c == fieldSeparator:
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	fields = append(fields, b.String())
+	return fields, nil
+}
+
+// encodeTodo encodes a todo as a v2 record line (without trailing newline)
+func encodeTodo(todo Todo) string {
+	fields := []string{
+		strconv.Itoa(todo.ID),
+		escapeField(todo.Description),
+		strconv.FormatBool(todo.Completed),
+		todo.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, extra := range encodeExtraFields(todo) {
+		fields = append(fields, escapeField(extra[0]+"="+extra[1]))
+	}
+	return strings.Join(fields, string(fieldSeparator))
+}
+
+// encodeExtraFields returns the optional fields of a todo as key/value pairs
+func encodeExtraFields(todo Todo) [][2]string {
+	var extras [][2]string
+	if !todo.DeletedAt.IsZero() {
+		extras = append(extras, [2]string{fieldDeletedAt, todo.DeletedAt.Format(time.RFC3339)})
+	}
+	if !todo.StartAt.IsZero() {
+		extras = append(extras, [2]string{fieldStartAt, todo.StartAt.Format(time.RFC3339)})
+	}
+	if !todo.DueAt.IsZero() {
+		extras = append(extras, [2]string{fieldDueAt, todo.DueAt.Format(time.RFC3339)})
+	}
+	if todo.Priority != PriorityNone {
+		extras = append(extras, [2]string{fieldPriority, todo.Priority.String()})
+	}
+	if len(todo.Tags) > 0 {
+		extras = append(extras, [2]string{fieldTags, strings.Join(todo.Tags, ",")})
+	}
+	if todo.ParentID != 0 {
+		extras = append(extras, [2]string{fieldParentID, strconv.Itoa(todo.ParentID)})
+	}
+	if !todo.Recurrence.IsZero() {
+		extras = append(extras, [2]string{fieldRepeat, todo.Recurrence.String()})
+	}
+	if todo.Position != 0 {
+		extras = append(extras, [2]string{fieldPosition, strconv.Itoa(todo.Position)})
+	}
+	if !todo.CompletedAt.IsZero() {
+		extras = append(extras, [2]string{fieldCompleted, todo.CompletedAt.Format(time.RFC3339)})
+	}
+	if len(todo.Completions) > 0 {
+		events := make([]string, len(todo.Completions))
+		for i, event := range todo.Completions {
+			events[i] = event.String()
+		}
+		extras = append(extras, [2]string{fieldHistory, strings.Join(events, ",")})
+	}
+	return extras
+}
+
+// decodeExtraField sets an optional field on a todo. Unknown keys and
+// malformed values are ignored so that newer files stay readable.
+func decodeExtraField(todo *Todo, key, value string) {
+	switch key {
+	case fieldDeletedAt:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			todo.DeletedAt = t
+		}
+	case fieldStartAt:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			todo.StartAt = t
+		}
+	case fieldDueAt:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			todo.DueAt = t
+		}
+	case fieldPriority:
+		if p, err := ParsePriority(value); err == nil {
+			todo.Priority = p
+		}
+	case fieldTags:
+		todo.Tags = normalizeTags(strings.Split(value, ","))
+	case fieldParentID:
+		if id, err := strconv.Atoi(value); err == nil {
+			todo.ParentID = id
+		}
+	case fieldRepeat:
+		if rule, err := ParseRecurrence(value); err == nil {
+			todo.Recurrence = rule
+		}
+	case fieldPosition:
+		if position, err := strconv.Atoi(value); err == nil {
+			todo.Position = position
+		}
+	case fieldCompleted:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			todo.CompletedAt = t
+		}
+	case fieldHistory:
+		todo.Completions = nil
+		for _, field := range strings.Split(value, ",") {
+			if event, err := ParseCompletionEvent(field); err == nil {
+				todo.Completions = append(todo.Completions, event)
+			}
+		}
+	}
+}
+
+// decodeTodo decodes a record line written in the given format version
+func decodeTodo(line string, version int) (Todo, error) {
+	var parts []string
+	if version >= formatV2 {
+		fields, err := splitEscapedRecord(line)
+		if err != nil {
+			return Todo{}, err
+		}
+		parts = fields
+	} else {
+		parts = strings.SplitN(line, "|", 4)
+	}
+
+	if len(parts) < 4 {
+		return Todo{}, fmt.Errorf("bản ghi thiếu trường: %q", line)
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Todo{}, fmt.Errorf("ID không hợp lệ: %q", parts[0])
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, parts[3])
+	if err != nil {
+		createdAt = time.Now()
+	}
+
+	todo := Todo{
+		ID:          id,
+		Description: parts[1],
+		Completed:   parts[2] == "true",
+		CreatedAt:   createdAt,
+	}
+
+	for _, extra := range parts[4:] {
+		if key, value, ok := strings.Cut(extra, "="); ok {
+			decodeExtraField(&todo, key, value)
+		}
+	}
+	return todo, nil
+}
+
+// parseFormatHeader returns the format version declared by a header line
+func parseFormatHeader(line string) (int, bool) {
+	if !strings.HasPrefix(line, formatHeaderPrefix) {
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, formatHeaderPrefix)))
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// readLines reads every line from r without the line-length limit of
+// bufio.Scanner, stripping the trailing line break
+func readLines(r io.Reader) ([]string, error) {
+	reader := bufio.NewReader(r)
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+// parseTodoFile splits the lines of a list file into header metadata and
+// todos. Files without a format header are read as version 1.
+func parseTodoFile(lines []string) (header []string, todos []Todo) {
+	version := formatV1
+	inHeader := true
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "#") {
+			if v, ok := parseFormatHeader(trimmed); ok {
+				version = v
+				continue
+			}
+			if inHeader {
+				header = append(header, line)
+			}
+			continue
+		}
+
+		if trimmed == "" {
+			// Keep empty lines that separate header metadata from todos
+			if inHeader && len(header) > 0 {
+				header = append(header, line)
+			}
+			continue
+		}
+
+		inHeader = false
+
+		recordLine := trimmed
+		if version >= formatV2 {
+			// Leading/trailing spaces in a v2 description are significant
+			recordLine = line
+		}
+
+		todo, err := decodeTodo(recordLine, version)
+		if err != nil {
+			fmt.Printf("⚠️ Bỏ qua dòng không hợp lệ: %v\n", err)
+			continue
+		}
+		todos = append(todos, todo)
+	}
+
+	return header, todos
+}
+
+// writeTodoFile writes header metadata and todos in the current format
+func writeTodoFile(w io.Writer, header []string, todos []Todo) error {
+	writer := bufio.NewWriter(w)
+
+	if _, err := fmt.Fprintf(writer, "%s%d\n", formatHeaderPrefix, currentFormat); err != nil {
+		return err
+	}
+
+	for _, headerLine := range header {
+		if _, err := writer.WriteString(headerLine + "\n"); err != nil {
+			return err
+		}
+	}
+
+	for _, todo := range todos {
+		if _, err := writer.WriteString(encodeTodo(todo) + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
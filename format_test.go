@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscapeField(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Mua sữa", "Mua sữa"},
+		{"a|b", `a\|b`},
+		{`C:\tmp`, `C:\\tmp`},
+		{"dòng 1\ndòng 2\r\n", `dòng 1\ndòng 2\r\n`},
+		{`\|`, `\\\|`},
+	}
+
+	for _, test := range tests {
+		got := escapeField(test.in)
+		if got != test.want {
+			t.Errorf("escapeField(%q) = %q, want %q", test.in, got, test.want)
+		}
+		fields, err := splitEscapedRecord(got)
+		if err != nil || len(fields) != 1 || fields[0] != test.in {
+			t.Errorf("splitEscapedRecord(%q) = %q, %v", got, fields, err)
+		}
+	}
+}
+
+func TestSplitEscapedRecordErrors(t *testing.T) {
+	for _, line := range []string{`abc\`, `a\tb`} {
+		if _, err := splitEscapedRecord(line); err == nil {
+			t.Errorf("splitEscapedRecord(%q) succeeded", line)
+		}
+	}
+}
+
+func TestTodoRoundTrip(t *testing.T) {
+	created := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+	done := created.Add(26 * time.Hour)
+	rule, _ := ParseRecurrence("FREQ=MONTHLY;BYMONTHDAY=1,15")
+
+	todos := []Todo{
+		{ID: 1, Description: "Mua sữa", CreatedAt: created},
+		{ID: 2, Description: "  a|b\\c\nd  ", Completed: true, CreatedAt: created, CompletedAt: done,
+			Completions: []CompletionEvent{{Event: EventCompleted, At: done}}},
+		{ID: 3, Description: "đủ trường", CreatedAt: created, DeletedAt: done,
+			StartAt: time.Date(2025, 10, 3, 0, 0, 0, 0, time.Local), DueAt: time.Date(2025, 10, 5, 0, 0, 0, 0, time.Local),
+			Priority: PriorityUrgent, Tags: []string{"nhà", "xe"}, ParentID: 1, Recurrence: rule, Position: 4},
+	}
+
+	for _, todo := range todos {
+		line := encodeTodo(todo)
+		got, err := decodeTodo(line, formatV2)
+		if err != nil {
+			t.Errorf("decodeTodo(%q): %v", line, err)
+			continue
+		}
+		if encodeTodo(got) != line || got.Description != todo.Description {
+			t.Errorf("round trip of %q gave %+v", line, got)
+		}
+	}
+}
+
+func TestDecodeTodoV1(t *testing.T) {
+	tests := []struct {
+		line string
+		want Todo
+	}{
+		{"1|Mua sữa|false|2025-10-01T08:30:00Z", Todo{ID: 1, Description: "Mua sữa", CreatedAt: time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)}},
+		{"2|Xong|true|2025-10-01T08:30:00Z", Todo{ID: 2, Description: "Xong", Completed: true, CreatedAt: time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)}},
+		// No escaping in v1: a backslash is text
+		{`3|C:\tmp|false|2025-10-01T08:30:00Z`, Todo{ID: 3, Description: `C:\tmp`, CreatedAt: time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)}},
+	}
+
+	for _, test := range tests {
+		got, err := decodeTodo(test.line, formatV1)
+		if err != nil {
+			t.Errorf("decodeTodo(%q, v1): %v", test.line, err)
+			continue
+		}
+		if encodeTodo(got) != encodeTodo(test.want) {
+			t.Errorf("decodeTodo(%q, v1) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+
+	for _, line := range []string{"1|kém trường", "x|Sai ID|false|2025-10-01T08:30:00Z"} {
+		if _, err := decodeTodo(line, formatV1); err == nil {
+			t.Errorf("decodeTodo(%q, v1) succeeded", line)
+		}
+	}
+}
+
+func TestTodoFile(t *testing.T) {
+	v1 := "# Color: red\n\n1|Một|false|2025-10-01T08:30:00Z\n2|Hai|true|2025-10-01T08:30:00Z\n"
+	header, todos := parseTodoFile(strings.Split(v1, "\n"))
+	if len(todos) != 2 || todos[1].Description != "Hai" || !todos[1].Completed {
+		t.Fatalf("v1 todos = %+v", todos)
+	}
+
+	// Upgraded to v2 on write, keeping the header
+	var buf bytes.Buffer
+	if err := writeTodoFile(&buf, header, todos); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), formatHeaderPrefix+"2\n# Color: red\n") {
+		t.Errorf("v2 file starts with %q", buf.String())
+	}
+
+	lines, err := readLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotHeader, got := parseTodoFile(lines)
+	if strings.Join(gotHeader, "\n") != strings.Join(header, "\n") || len(got) != len(todos) {
+		t.Fatalf("v2 file read back as %q, %+v", gotHeader, got)
+	}
+	for i := range got {
+		if encodeTodo(got[i]) != encodeTodo(todos[i]) {
+			t.Errorf("todo %d read back as %+v, want %+v", i, got[i], todos[i])
+		}
+	}
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar (RFC 5545) todos. Every todo is a VTODO component:
+//
+//	BEGIN:VTODO
+//	UID:todo-3@todoapp
+//	SUMMARY:Mua sữa
+//	STATUS:NEEDS-ACTION
+//	DUE;VALUE=DATE:20251005
+//	END:VTODO
+//
+// Dates of the app have no time of day, so DUE and DTSTART are written as
+// dates, and times read from a file are cut to their local day.
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405"
+	icalUTC      = "20060102T150405Z"
+
+	icalLineLength = 75 // Octets per line before folding, without CRLF
+	icalProdID     = "-//todoapp//Todo App//VI"
+	icalUIDDomain  = "todoapp"
+
+	icalNeedsAction = "NEEDS-ACTION"
+	icalCompleted   = "COMPLETED"
+	icalCancelled   = "CANCELLED"
+)
+
+// icalPriorities are the iCalendar priorities written for the low, medium,
+// high and urgent priorities; 1 is the highest, 9 the lowest
+var icalPriorities = map[Priority]int{
+	PriorityLow:    9,
+	PriorityMedium: 5,
+	PriorityHigh:   3,
+	PriorityUrgent: 1,
+}
+
+// icalProperty is a content line: NAME;PARAM=value:value
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalComponent is a BEGIN:…/END:… block with its properties and nested
+// components
+type icalComponent struct {
+	Name       string
+	Properties []icalProperty
+	Components []*icalComponent
+}
+
+// Property returns the first property with the given name
+func (c *icalComponent) Property(name string) (icalProperty, bool) {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return icalProperty{}, false
+}
+
+// Value returns the value of the first property with the given name, or ""
+func (c *icalComponent) Value(name string) string {
+	p, _ := c.Property(name)
+	return p.Value
+}
+
+// escapeICalText escapes a TEXT value
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeICalText undoes escapeICalText
+func unescapeICalText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitICalList splits a comma separated list of TEXT values, keeping
+// escaped commas
+func splitICalList(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescapeICalText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescapeICalText(s[start:]))
+}
+
+// foldICalLine folds a content line into lines of at most icalLineLength
+// octets, continuation lines starting with a space, without splitting a
+// UTF-8 character
+func foldICalLine(line string) string {
+	var b strings.Builder
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// parseICalLine parses an unfolded content line. Parameter values may be
+// quoted, so that they can contain ":", ";" and ",".
+func parseICalLine(line string) (icalProperty, error) {
+	p := icalProperty{Params: make(map[string]string)}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("dòng không hợp lệ: %q", line)
+	}
+	p.Name = strings.ToUpper(line[:i])
+
+	rest := line[i:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return p, fmt.Errorf("tham số không hợp lệ trong %s", p.Name)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("thiếu dấu \" đóng trong %s", p.Name)
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return p, fmt.Errorf("thiếu giá trị của %s", p.Name)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		p.Params[key] = value
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return p, fmt.Errorf("thiếu giá trị của %s", p.Name)
+	}
+	p.Value = rest[1:]
+	return p, nil
+}
+
+// readICalLines reads the content lines of a file, unfolding the lines
+// that start with a space or a tab
+func readICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICal parses the components of a file. The result is a root
+// component holding the VCALENDAR components.
+func parseICal(r io.Reader) (*icalComponent, error) {
+	lines, err := readICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &icalComponent{}
+	stack := []*icalComponent{root}
+	for _, line := range lines {
+		p, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+		current := stack[len(stack)-1]
+		switch p.Name {
+		case "BEGIN":
+			c := &icalComponent{Name: strings.ToUpper(p.Value)}
+			current.Components = append(current.Components, c)
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 1 || current.Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("END:%s không khớp với BEGIN:%s", p.Value, current.Name)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			current.Properties = append(current.Properties, p)
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("thiếu END:%s", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// icalZones resolves the TZID parameters of a file. Known IANA names are
+// looked up in the time zone database; other names, such as those of
+// Outlook, use the standard offset of the VTIMEZONE of the file.
+type icalZones map[string]*time.Location
+
+// newICalZones collects the VTIMEZONE components of a calendar
+func newICalZones(calendar *icalComponent) icalZones {
+	zones := make(icalZones)
+	for _, c := range calendar.Components {
+		if c.Name != "VTIMEZONE" {
+			continue
+		}
+		tzid := c.Value("TZID")
+		for _, sub := range c.Components {
+			if sub.Name != "STANDARD" {
+				continue
+			}
+			if offset, ok := parseICalOffset(sub.Value("TZOFFSETTO")); ok {
+				zones[tzid] = time.FixedZone(tzid, offset)
+			}
+		}
+	}
+	return zones
+}
+
+// parseICalOffset parses a UTC offset such as +0700 or -053000 in seconds
+func parseICalOffset(value string) (int, bool) {
+	if len(value) != 5 && len(value) != 7 || (value[0] != '+' && value[0] != '-') {
+		return 0, false
+	}
+	digits, err := strconv.Atoi(value[1:])
+	if err != nil {
+		return 0, false
+	}
+	if len(value) == 5 {
+		digits *= 100
+	}
+	offset := digits/10000*3600 + digits/100%100*60 + digits%100
+	if value[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
+
+// location returns the location of a TZID, the local time zone if unknown
+func (zones icalZones) location(tzid string) *time.Location {
+	if tzid == "" {
+		return time.Local
+	}
+	if loc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+		return loc
+	}
+	if loc, ok := zones[tzid]; ok {
+		return loc
+	}
+	return time.Local
+}
+
+// parseTime parses a DATE or DATE-TIME property. A UTC time ends with Z, a
+// time with a TZID is in that zone, any other time and a date are local.
+func (zones icalZones) parseTime(p icalProperty) (time.Time, error) {
+	value := p.Value
+	if p.Params["VALUE"] == "DATE" || len(value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, value, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ngày %s không hợp lệ: %q", p.Name, value)
+		}
+		return t, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalUTC, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("thời gian %s không hợp lệ: %q", p.Name, value)
+		}
+		return t, nil
+	}
+	t, err := time.ParseInLocation(icalDateTime, value, zones.location(p.Params["TZID"]))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("thời gian %s không hợp lệ: %q", p.Name, value)
+	}
+	return t, nil
+}
+
+// icalUID returns the UID of a todo of a list, unique across the lists
+func icalUID(todo Todo, project string) string {
+	if project == "" {
+		return fmt.Sprintf("todo-%d@%s", todo.ID, icalUIDDomain)
+	}
+	return fmt.Sprintf("todo-%d.%s@%s", todo.ID, url.PathEscape(project), icalUIDDomain)
+}
+
+// icalRRule returns the RRULE of a repeat rule. Only the frequencies of
+// RFC 5545 are written; the others have no meaning for a calendar app.
+func icalRRule(rule Recurrence) string {
+	switch rule.Freq {
+	case RepeatDaily, RepeatWeekly, RepeatMonthly:
+		return rule.String()
+	}
+	return ""
+}
+
+// parseICalRRule reads an RRULE, taking the day of the week or of the
+// month of the due date when the rule leaves it out
+func parseICalRRule(value string, due time.Time) (Recurrence, bool) {
+	rule, err := ParseRecurrence(value)
+	if err == nil {
+		return rule, rule.Freq == RepeatDaily || rule.Freq == RepeatWeekly || rule.Freq == RepeatMonthly
+	}
+	if due.IsZero() {
+		return Recurrence{}, false
+	}
+	switch {
+	case strings.Contains(value, RepeatWeekly) && !strings.Contains(value, "BYDAY"):
+		value += ";BYDAY=" + rruleWeekdays[due.Weekday()]
+	case strings.Contains(value, RepeatMonthly) && !strings.Contains(value, "BYMONTHDAY"):
+		value += ";BYMONTHDAY=" + strconv.Itoa(due.Day())
+	default:
+		return Recurrence{}, false
+	}
+	rule, err = ParseRecurrence(value)
+	return rule, err == nil
+}
+
+// WriteICal writes todos, outside the trash, as an iCalendar file of VTODO
+// components. project is the project of the list, "" for the main list.
+func WriteICal(w io.Writer, todos []Todo, project string) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		bw.WriteString(foldICalLine(name + ":" + value))
+	}
+	date := func(name string, t time.Time) {
+		if !t.IsZero() {
+			line(name+";VALUE=DATE", t.Local().Format(icalDate))
+		}
+	}
+	utc := func(name string, t time.Time) {
+		if !t.IsZero() {
+			line(name, t.UTC().Format(icalUTC))
+		}
+	}
+
+	now := time.Now()
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", icalProdID)
+	if project != "" {
+		line("X-WR-CALNAME", escapeICalText(project))
+	}
+	for _, todo := range todos {
+		if todo.InTrash() {
+			continue
+		}
+		line("BEGIN", "VTODO")
+		line("UID", icalUID(todo, project))
+		utc("DTSTAMP", now)
+		line("SUMMARY", escapeICalText(todo.Description))
+		if todo.Completed {
+			line("STATUS", icalCompleted)
+			utc("COMPLETED", todo.CompletedAt)
+		} else {
+			line("STATUS", icalNeedsAction)
+		}
+		utc("CREATED", todo.CreatedAt)
+		date("DTSTART", todo.StartAt)
+		date("DUE", todo.DueAt)
+		if priority, ok := icalPriorities[todo.Priority]; ok {
+			line("PRIORITY", strconv.Itoa(priority))
+		}
+		if len(todo.Tags) > 0 {
+			tags := make([]string, len(todo.Tags))
+			for i, tag := range todo.Tags {
+				tags[i] = escapeICalText(tag)
+			}
+			line("CATEGORIES", strings.Join(tags, ","))
+		}
+		if rrule := icalRRule(todo.Recurrence); rrule != "" {
+			line("RRULE", rrule)
+		}
+		if todo.ParentID != 0 {
+			line("RELATED-TO;RELTYPE=PARENT", icalUID(Todo{ID: todo.ParentID}, project))
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// parseICalPriority maps an iCalendar priority to a priority of the app:
+// 1 is urgent, 2-4 high, 5 medium and 6-9 low
+func parseICalPriority(value string) Priority {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || n <= 0 || n > 9:
+		return PriorityNone
+	case n == 1:
+		return PriorityUrgent
+	case n < 5:
+		return PriorityHigh
+	case n == 5:
+		return PriorityMedium
+	}
+	return PriorityLow
+}
+
+// decodeICalTodo reads a VTODO component
+func decodeICalTodo(c *icalComponent, zones icalZones) (Todo, error) {
+	var todo Todo
+	todo.Description = strings.Join(strings.Fields(unescapeICalText(c.Value("SUMMARY"))), " ")
+	if todo.Description == "" {
+		return Todo{}, fmt.Errorf("thiếu SUMMARY")
+	}
+
+	status := strings.ToUpper(c.Value("STATUS"))
+	if status == icalCancelled {
+		return Todo{}, fmt.Errorf("%q đã bị hủy (CANCELLED)", todo.Description)
+	}
+
+	var err error
+	timeOf := func(name string, day bool) time.Time {
+		p, ok := c.Property(name)
+		if !ok || err != nil {
+			return time.Time{}
+		}
+		var t time.Time
+		t, err = zones.parseTime(p)
+		if day && err == nil {
+			t = startOfDay(t)
+		}
+		return t
+	}
+	todo.CreatedAt = timeOf("CREATED", false)
+	todo.CompletedAt = timeOf("COMPLETED", false)
+	todo.StartAt = timeOf("DTSTART", true)
+	todo.DueAt = timeOf("DUE", true)
+	if err != nil {
+		return Todo{}, err
+	}
+	todo.Completed = status == icalCompleted || (status == "" && !todo.CompletedAt.IsZero())
+	if !todo.Completed {
+		todo.CompletedAt = time.Time{}
+	}
+
+	todo.Priority = parseICalPriority(c.Value("PRIORITY"))
+	for _, p := range c.Properties {
+		if p.Name == "CATEGORIES" {
+			// Tags cannot contain spaces, categories often do
+			for _, category := range splitICalList(p.Value) {
+				todo.Tags = addTag(todo.Tags, strings.Join(strings.Fields(category), "-"))
+			}
+		}
+	}
+	if rrule := c.Value("RRULE"); rrule != "" {
+		if rule, ok := parseICalRRule(rrule, todo.DueAt); ok {
+			todo.Recurrence = rule
+		}
+	}
+	return todo, nil
+}
+
+// ReadICal reads the VTODO components of an iCalendar file. Components that
+// cannot be read are reported, with their number, and skipped; other
+// components, such as events, are ignored. A todo related to another one
+// as its parent (RELATED-TO) becomes its subtask. The todos have
+// placeholder IDs below 1, see ImportTodos.
+func ReadICal(r io.Reader) ([]Todo, []error) {
+	root, err := parseICal(r)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var todos []Todo
+	var errs []error
+	ids := make(map[string]int)
+	parents := make(map[int]string)
+	n := 0
+	for _, calendar := range root.Components {
+		if calendar.Name != "VCALENDAR" {
+			continue
+		}
+		zones := newICalZones(calendar)
+		for _, c := range calendar.Components {
+			if c.Name != "VTODO" {
+				continue
+			}
+			n++
+			todo, err := decodeICalTodo(c, zones)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("VTODO %d: %v", n, err))
+				continue
+			}
+
+			todo.ID = -(len(todos) + 1)
+			if uid := c.Value("UID"); uid != "" {
+				ids[uid] = todo.ID
+			}
+			for _, p := range c.Properties {
+				if reltype := strings.ToUpper(p.Params["RELTYPE"]); p.Name == "RELATED-TO" && (reltype == "" || reltype == "PARENT") {
+					parents[todo.ID] = p.Value
+				}
+			}
+			todo.Position = len(todos) + 1
+			todos = append(todos, todo)
+		}
+	}
+
+	for i := range todos {
+		if uid, ok := parents[todos[i].ID]; ok {
+			todos[i].ParentID = ids[uid]
+		}
+	}
+	return todos, errs
+}
+
+// exportICal saves the list, without the trash, as an iCalendar file
+func (app *TodoApp) exportICal(isProject bool) {
+	project := app.projectKey(isProject)
+	todos := app.listFor(project).GetTodos()
+	app.exportFile(app.transferFileName(isProject, ".ics"), func(w io.Writer) error {
+		return WriteICal(w, todos, project)
+	})
+}
+
+// importICal adds the todos of an iCalendar file to the list
+func (app *TodoApp) importICal(isProject bool) {
+	project := app.projectKey(isProject)
+	app.importFile(func(r io.Reader) {
+		todos, errs := ReadICal(r)
+		app.confirmImport(project, todos, errs)
+	})
+}
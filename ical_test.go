@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestICalText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Mua sữa", "Mua sữa"},
+		{"a,b;c", `a\,b\;c`},
+		{`C:\tmp`, `C:\\tmp`},
+		{"dòng 1\ndòng 2", `dòng 1\ndòng 2`},
+	}
+
+	for _, test := range tests {
+		got := escapeICalText(test.in)
+		if got != test.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", test.in, got, test.want)
+		}
+		if back := unescapeICalText(got); back != test.in {
+			t.Errorf("unescapeICalText(%q) = %q, want %q", got, back, test.in)
+		}
+	}
+
+	if got := splitICalList(`nhà,công việc\,riêng,xe`); !slices.Equal(got, []string{"nhà", "công việc,riêng", "xe"}) {
+		t.Errorf("splitICalList = %q", got)
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("Việc cần làm ", 20)
+	folded := foldICalLine(line)
+
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > icalLineLength {
+			t.Errorf("line of %d octets: %q", len(part), part)
+		}
+		if !utf8.ValidString(part) {
+			t.Errorf("line splits a character: %q", part)
+		}
+	}
+	lines, err := readICalLines(strings.NewReader(folded))
+	if err != nil || len(lines) != 1 || lines[0] != line {
+		t.Errorf("unfolded to %q, %v", lines, err)
+	}
+}
+
+func TestParseICalLine(t *testing.T) {
+	p, err := parseICalLine(`DUE;TZID="GMT+7: Hà Nội";VALUE=DATE-TIME:20251005T090000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "DUE" || p.Params["TZID"] != "GMT+7: Hà Nội" || p.Params["VALUE"] != "DATE-TIME" || p.Value != "20251005T090000" {
+		t.Errorf("parseICalLine = %+v", p)
+	}
+
+	for _, line := range []string{"SUMMARY", ":x", `DUE;TZID="a:1`, "DUE;TZID:1"} {
+		if _, err := parseICalLine(line); err == nil {
+			t.Errorf("parseICalLine(%q) succeeded", line)
+		}
+	}
+}
+
+func TestParseICalPriority(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Priority
+	}{
+		{"", PriorityNone},
+		{"0", PriorityNone},
+		{"1", PriorityUrgent},
+		{"2", PriorityHigh},
+		{"4", PriorityHigh},
+		{" 5 ", PriorityMedium},
+		{"6", PriorityLow},
+		{"9", PriorityLow},
+		{"10", PriorityNone},
+		{"cao", PriorityNone},
+	}
+
+	for _, test := range tests {
+		if got := parseICalPriority(test.value); got != test.want {
+			t.Errorf("parseICalPriority(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestICalRoundTrip(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 10, d, 0, 0, 0, 0, time.Local)
+	}
+	created := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+	weekly, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,WE")
+	todos := []Todo{
+		{ID: 1, Description: "Chuẩn bị, kế hoạch; quý 4", Tags: []string{"team", "q4"}, Priority: PriorityHigh,
+			DueAt: day(5), StartAt: day(3), CreatedAt: created, Recurrence: weekly},
+		{ID: 2, Description: `Viết dàn ý C:\tmp`, ParentID: 1, Completed: true, CompletedAt: created.Add(time.Hour),
+			CreatedAt: created, Priority: PriorityUrgent},
+		{ID: 3, Description: "Đặt phòng họp", Priority: PriorityLow},
+		{ID: 4, Description: "Trung bình", Priority: PriorityMedium},
+		{ID: 5, Description: "Trong thùng rác", DeletedAt: day(4)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, todos, "Dự án X"); err != nil {
+		t.Fatal(err)
+	}
+	got, errs := ReadICal(&buf)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	// Trashed todos are not exported; IDs become placeholders
+	if len(got) != 4 {
+		t.Fatalf("read %d todos: %+v", len(got), got)
+	}
+	for i, want := range todos[:4] {
+		todo := got[i]
+		if todo.ID != -(i+1) || todo.Position != i+1 {
+			t.Errorf("todo %d has ID %d and position %d", i, todo.ID, todo.Position)
+		}
+		if todo.Description != want.Description || todo.Completed != want.Completed || todo.Priority != want.Priority ||
+			!slices.Equal(todo.Tags, want.Tags) || todo.Recurrence.String() != want.Recurrence.String() ||
+			!todo.DueAt.Equal(want.DueAt) || !todo.StartAt.Equal(want.StartAt) ||
+			!todo.CreatedAt.Equal(want.CreatedAt) || !todo.CompletedAt.Equal(want.CompletedAt) {
+			t.Errorf("read %+v, want %+v", todo, want)
+		}
+	}
+	if got[1].ParentID != got[0].ID || got[0].ParentID != 0 {
+		t.Errorf("subtask lost its parent: %+v", got)
+	}
+}
+
+func TestReadICal(t *testing.T) {
+	text := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:SE Asia Standard Time",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:+0700",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"SUMMARY:Không phải việc",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:a",
+		"SUMMARY:Họp   nhóm",
+		"DUE;TZID=SE Asia Standard Time:20251005T230000",
+		"RRULE:FREQ=WEEKLY",
+		"CATEGORIES:Công việc,nhà",
+		"PRIORITY:2",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:b",
+		"SUMMARY:Bị hủy",
+		"STATUS:CANCELLED",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"DESCRIPTION:Không có tiêu đề",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:c",
+		"SUMMARY:Xong",
+		"COMPLETED:20251002T100000Z",
+		"RELATED-TO:a",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Ngày sai",
+		"DUE;VALUE=DATE:20251345",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	todos, errs := ReadICal(strings.NewReader(text))
+
+	due := startOfDay(time.Date(2025, 10, 5, 23, 0, 0, 0, time.FixedZone("", 7*3600)))
+	rule, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=" + rruleWeekdays[due.Weekday()])
+	want := []Todo{
+		{ID: -1, Description: "Họp nhóm", DueAt: due, Recurrence: rule, Tags: []string{"công-việc", "nhà"}, Priority: PriorityHigh, Position: 1},
+		{ID: -2, Description: "Xong", Completed: true, CompletedAt: time.Date(2025, 10, 2, 10, 0, 0, 0, time.UTC), ParentID: -1, Position: 2},
+	}
+	if !sameTodos(todos, want) {
+		t.Errorf("todos = %+v, want %+v", todos, want)
+	}
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	if len(errs) != 3 || !strings.HasPrefix(messages[0], "VTODO 2:") || !strings.HasPrefix(messages[1], "VTODO 3: thiếu SUMMARY") ||
+		!strings.HasPrefix(messages[2], "VTODO 5:") {
+		t.Errorf("errors = %q", messages)
+	}
+}
+
+func TestReadICalBroken(t *testing.T) {
+	for _, text := range []string{
+		"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\nEND:VTODO\r\n",
+		"không phải lịch\r\n",
+	} {
+		if todos, errs := ReadICal(strings.NewReader(text)); len(todos) != 0 || len(errs) != 1 {
+			t.Errorf("ReadICal(%q) = %+v, %v", text, todos, errs)
+		}
+	}
+}
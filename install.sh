@@ -36,15 +36,15 @@ cp "$ICON_FILE" "$ICON_BASE_DIR/$ICON_FILE"
 
 
 echo "🖥️ Creating desktop entry..."
-# Create desktop file dynamically with current project path
+# The app resolves its data directory itself, whatever the working directory
 cat > ~/.local/share/applications/"$DESKTOP_FILE" << EOF
 [Desktop Entry]
 Version=1.0
 Type=Application
 Name=Todo List App
 Comment=Desktop Todo List Application with Project Management
-Exec=bash -c "cd '$CURRENT_DIR' && ./$APP_NAME"
-Icon=$CURRENT_DIR/$ICON_FILE
+Exec=$INSTALL_DIR/$APP_NAME
+Icon=$HICOLOR_DIR/$ICON_FILE
 Terminal=false
 Categories=Office;Utility;
 Keywords=todo;task;project;productivity;
@@ -59,3 +59,10 @@ if command -v update-desktop-database >/dev/null 2>&1; then
     update-desktop-database ~/.local/share/applications/ 2>/dev/null
     echo "   ✅ Desktop database updated"
 fi
+
+# Older versions kept their data in this directory; the app moves it to its
+# data directory when started here once
+if [ -e "$CURRENT_DIR/todos.txt" ]; then
+    echo "ℹ️ Found data of an older version. Run the app once from this directory to move it:"
+    echo "   cd '$CURRENT_DIR' && '$INSTALL_DIR/$APP_NAME'"
+fi
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Journal operation kinds
+const (
+	OpAdd      = "add"
+	OpComplete = "complete"
+	OpDelete   = "delete" // Permanent deletion
+	OpEdit     = "edit"
+	OpTrash    = "trash" // Moved to the trash; At is the deletion time
+	OpRestore  = "restore"
+	OpReopen   = "reopen" // Completed todo marked as not done
+)
+
+// journalCompactThreshold is the number of journal entries after which the
+// journal is folded into the list snapshot
+const journalCompactThreshold = 200
+
+// JournalOp is one entry of a list's operation journal
+type JournalOp struct {
+	Op   string    `json:"op"`
+	ID   int       `json:"id"`
+	At   time.Time `json:"at"`
+	Todo *Todo     `json:"todo,omitempty"` // Full todo for add, edit, complete and reopen
+}
+
+// Journal is an append-only log of operations on one list, stored as JSON
+// lines. Operations that have been compacted into the snapshot are moved to a
+// history file so that the full history of every todo is kept.
+type Journal struct {
+	path  string
+	count int // Number of entries not yet compacted
+}
+
+// OpenJournal opens the journal stored at path
+func OpenJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// historyPath returns the file holding compacted journal entries
+func (j *Journal) historyPath() string {
+	return j.path + ".history"
+}
+
+// Len returns the number of entries waiting to be compacted
+func (j *Journal) Len() int {
+	return j.count
+}
+
+// Append writes an operation to the end of the journal and syncs it to disk
+func (j *Journal) Append(op JournalOp) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	if err := appendLines(j.path, [][]byte{data}); err != nil {
+		return err
+	}
+	j.count++
+	return nil
+}
+
+// Load reads the pending journal entries. A torn last line left by a crash
+// is ignored.
+func (j *Journal) Load() ([]JournalOp, error) {
+	ops, err := readJournalFile(j.path)
+	j.count = len(ops)
+	return ops, err
+}
+
+// Compact moves the pending entries to the history file and empties the
+// journal. It must only be called after the snapshot has been saved.
+func (j *Journal) Compact() error {
+	ops, err := readJournalFile(j.path)
+	if err != nil {
+		return err
+	}
+
+	if len(ops) > 0 {
+		var lines [][]byte
+		for _, op := range ops {
+			data, err := json.Marshal(op)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, data)
+		}
+		if err := appendLines(j.historyPath(), lines); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	j.count = 0
+	return nil
+}
+
+// History returns every recorded operation on a todo, oldest first
+func (j *Journal) History(id int) []JournalOp {
+	var history []JournalOp
+	for _, path := range []string{j.historyPath(), j.path} {
+		ops, _ := readJournalFile(path)
+		for _, op := range ops {
+			if op.ID == id {
+				history = append(history, op)
+			}
+		}
+	}
+	return history
+}
+
+// Remove deletes the journal and its history
+func (j *Journal) Remove() error {
+	for _, path := range []string{j.path, j.historyPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	j.count = 0
+	return nil
+}
+
+// appendLines appends lines to a file and syncs it. A torn last line left
+// by a crash is cut off first, otherwise it would join the first new line.
+func appendLines(path string, lines [][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if err := truncateTornLine(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, line := range lines {
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// truncateTornLine cuts a file after its last newline
+func truncateTornLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for end > 0 {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+
+	if end == info.Size() {
+		return nil
+	}
+	fmt.Printf("⚠️ Bỏ dòng chưa ghi xong ở cuối %s\n", file.Name())
+	return file.Truncate(end)
+}
+
+// readJournalFile reads the entries of a journal file; a missing file is empty
+func readJournalFile(path string) ([]JournalOp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := readLines(file)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var ops []JournalOp
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var op JournalOp
+		if err := json.Unmarshal([]byte(line), &op); err != nil {
+			if i == len(lines)-1 {
+				// Torn write from a crash: the operation never completed
+				fmt.Printf("⚠️ Bỏ qua dòng journal chưa ghi xong: %s\n", path)
+				continue
+			}
+			return nil, fmt.Errorf("journal %s hỏng ở dòng %d: %v", path, i+1, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// insertTodoByID inserts a todo keeping the slice ordered by ID, which is
+// the insertion order. Restored todos thus return to their original place.
+func insertTodoByID(todos []Todo, todo Todo) []Todo {
+	pos := len(todos)
+	for i := range todos {
+		if todos[i].ID > todo.ID {
+			pos = i
+			break
+		}
+	}
+
+	todos = append(todos, Todo{})
+	copy(todos[pos+1:], todos[pos:])
+	todos[pos] = todo
+	return todos
+}
+
+// applyOp replays a journal operation on a slice of todos. Operations are
+// idempotent so that replaying over a snapshot that already contains them
+// is harmless.
+func applyOp(todos []Todo, op JournalOp) []Todo {
+	index := -1
+	for i := range todos {
+		if todos[i].ID == op.ID {
+			index = i
+			break
+		}
+	}
+
+	switch op.Op {
+	case OpAdd, OpEdit, OpReopen, OpComplete:
+		if op.Todo == nil {
+			return todos
+		}
+		if index >= 0 {
+			todos[index] = *op.Todo
+		} else {
+			todos = insertTodoByID(todos, *op.Todo)
+		}
+	case OpDelete:
+		if index >= 0 {
+			todos = append(todos[:index], todos[index+1:]...)
+		}
+	case OpTrash:
+		if index >= 0 {
+			todos[index].DeletedAt = op.At
+		}
+	case OpRestore:
+		if index >= 0 {
+			todos[index].DeletedAt = time.Time{}
+		}
+	}
+	return todos
+}
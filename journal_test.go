@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyOp(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	one := Todo{ID: 1, Description: "một"}
+	two := Todo{ID: 2, Description: "hai"}
+	done := Todo{ID: 1, Description: "một", Completed: true, CompletedAt: at}
+
+	tests := []struct {
+		name  string
+		todos []Todo
+		op    JournalOp
+		want  []Todo
+	}{
+		{"add", []Todo{two}, JournalOp{Op: OpAdd, ID: 1, Todo: &one}, []Todo{one, two}},
+		{"add twice", []Todo{one}, JournalOp{Op: OpAdd, ID: 1, Todo: &one}, []Todo{one}},
+		{"complete", []Todo{one}, JournalOp{Op: OpComplete, ID: 1, Todo: &done}, []Todo{done}},
+		{"delete", []Todo{one, two}, JournalOp{Op: OpDelete, ID: 1}, []Todo{two}},
+		{"delete missing", []Todo{two}, JournalOp{Op: OpDelete, ID: 1}, []Todo{two}},
+		{"trash", []Todo{one}, JournalOp{Op: OpTrash, ID: 1, At: at}, []Todo{{ID: 1, Description: "một", DeletedAt: at}}},
+		{"restore", []Todo{{ID: 1, Description: "một", DeletedAt: at}}, JournalOp{Op: OpRestore, ID: 1}, []Todo{one}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			todos := append([]Todo(nil), test.todos...)
+			if got := applyOp(todos, test.op); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("applyOp = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJournalCompact(t *testing.T) {
+	journal := OpenJournal(filepath.Join(t.TempDir(), "todos.txt.journal"))
+	todo := Todo{ID: 1, Description: "một"}
+	ops := []JournalOp{
+		{Op: OpAdd, ID: 1, Todo: &todo},
+		{Op: OpDelete, ID: 1},
+	}
+	for _, op := range ops {
+		if err := journal.Append(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := journal.Load()
+	if err != nil || len(loaded) != 2 || journal.Len() != 2 {
+		t.Fatalf("Load = %d ops, %v", len(loaded), err)
+	}
+	if err := journal.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := journal.Load(); err != nil || len(loaded) != 0 {
+		t.Errorf("Load after Compact = %d ops, %v", len(loaded), err)
+	}
+	if history := journal.History(1); len(history) != 2 || history[1].Op != OpDelete {
+		t.Errorf("History = %+v", history)
+	}
+}
+
+func TestJournalTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.txt.journal")
+	journal := OpenJournal(path)
+	todo := Todo{ID: 1, Description: "một"}
+	if err := journal.Append(JournalOp{Op: OpAdd, ID: 1, Todo: &todo}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash in the middle of the next append
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"op":"delete","id":`)
+	file.Close()
+
+	if ops, err := journal.Load(); err != nil || len(ops) != 1 {
+		t.Fatalf("Load with torn line = %d ops, %v", len(ops), err)
+	}
+	if err := journal.Append(JournalOp{Op: OpTrash, ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := journal.Load()
+	if err != nil || len(ops) != 2 || ops[1].Op != OpTrash {
+		t.Fatalf("Load after append = %+v, %v", ops, err)
+	}
+	if err := journal.Compact(); err != nil {
+		t.Fatal(err)
+	}
+}
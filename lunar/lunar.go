@@ -0,0 +1,362 @@
+// Package lunar chuyển đổi giữa dương lịch và âm lịch Việt Nam.
+//
+// Thuật toán dựa trên cách tính của Hồ Ngọc Đức: điểm sóc (trăng mới) và
+// trung khí được tính theo giờ Việt Nam (UTC+7), tháng chứa Đông chí là
+// tháng 11 và năm có 13 tháng nhận tháng đầu tiên không chứa trung khí làm
+// tháng nhuận.
+package lunar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// timeZone là múi giờ dùng để tính âm lịch Việt Nam
+const timeZone = 7.0
+
+// location là múi giờ UTC+7 của các ngày dương lịch mà ToSolar trả về. Nửa
+// đêm theo giờ địa phương có thể không tồn tại (khi đổi múi giờ), nên ngày
+// được dựng theo giờ Việt Nam; nơi gọi lấy Year, Month, Day để đổi sang giờ
+// địa phương.
+var location = time.FixedZone("UTC+7", int(timeZone*3600))
+
+// synodicMonth là độ dài trung bình của một tháng âm lịch (ngày)
+const synodicMonth = 29.530588853
+
+// Date là một ngày âm lịch
+type Date struct {
+	Year  int  // Năm âm lịch
+	Month int  // Tháng 1-12
+	Day   int  // Ngày 1-30
+	Leap  bool // Tháng nhuận
+}
+
+// FromSolar trả về ngày âm lịch của ngày dương lịch t (theo ngày của t)
+func FromSolar(t time.Time) Date {
+	day, month, year, leap := solarToLunar(t.Day(), int(t.Month()), t.Year())
+	return Date{Year: year, Month: month, Day: day, Leap: leap}
+}
+
+// ToSolar trả về ngày dương lịch (0 giờ, giờ Việt Nam) của ngày âm lịch
+func (d Date) ToSolar() (time.Time, error) {
+	if d.Month < 1 || d.Month > 12 {
+		return time.Time{}, fmt.Errorf("tháng âm lịch không hợp lệ: %d", d.Month)
+	}
+
+	length, err := DaysInMonth(d.Year, d.Month, d.Leap)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d.Day < 1 || d.Day > length {
+		return time.Time{}, fmt.Errorf("tháng %s năm %d chỉ có %d ngày", MonthName(d.Month), d.Year, length)
+	}
+
+	start, ok := monthStart(d.Year, d.Month, d.Leap)
+	if !ok {
+		return time.Time{}, fmt.Errorf("năm %d không có tháng %d nhuận", d.Year, d.Month)
+	}
+	day, month, year := jdToDate(start + d.Day - 1)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, location), nil
+}
+
+// DaysInMonth trả về số ngày (29 hoặc 30) của một tháng âm lịch
+func DaysInMonth(year, month int, leap bool) (int, error) {
+	start, ok := monthStart(year, month, leap)
+	if !ok {
+		return 0, fmt.Errorf("năm %d không có tháng %d nhuận", year, month)
+	}
+	k := int(math.Floor((float64(start)-2415021.076998695)/synodicMonth + 0.5))
+	return newMoonDay(k+1) - start, nil
+}
+
+// LeapMonth trả về tháng nhuận của năm âm lịch, hoặc 0 nếu năm không nhuận
+func LeapMonth(year int) int {
+	// Tháng 1-10 nằm giữa tháng 11 của năm trước và tháng 11 của năm nay,
+	// tháng 11-12 nằm sau tháng 11 của năm nay
+	if month := leapMonthAfter(lunarMonth11(year-1), lunarMonth11(year)); month > 0 && month < 11 {
+		return month
+	}
+	if month := leapMonthAfter(lunarMonth11(year), lunarMonth11(year+1)); month >= 11 {
+		return month
+	}
+	return 0
+}
+
+// leapMonthAfter trả về tháng nhuận giữa hai tháng 11 bắt đầu ở a11 và b11,
+// hoặc 0 nếu không có
+func leapMonthAfter(a11, b11 int) int {
+	if b11-a11 <= 365 {
+		return 0
+	}
+	return leapMonthNumber(leapMonthOffset(a11))
+}
+
+// leapMonthNumber trả về số của tháng nhuận nằm ở vị trí leapOff tính từ
+// tháng 11; tháng nhuận mang số của tháng ngay trước nó
+func leapMonthNumber(leapOff int) int {
+	month := leapOff - 2
+	if month <= 0 {
+		month += 12
+	}
+	return month
+}
+
+// MonthsBetween trả về số tháng âm lịch (kể cả tháng nhuận) từ tháng chứa
+// ngày a đến tháng chứa ngày b
+func MonthsBetween(a, b time.Time) int {
+	return monthIndex(b) - monthIndex(a)
+}
+
+// monthIndex trả về số thứ tự của tháng âm lịch chứa ngày t
+func monthIndex(t time.Time) int {
+	return newMoonIndex(jdFromDate(t.Day(), int(t.Month()), t.Year()))
+}
+
+// newMoonIndex trả về số thứ tự k của điểm sóc bắt đầu tháng âm lịch chứa
+// ngày dayNumber. Ước lượng theo tháng trung bình có thể lệch một tháng về
+// cả hai phía.
+func newMoonIndex(dayNumber int) int {
+	k := int(math.Floor((float64(dayNumber) - 2415021.076998695) / synodicMonth))
+	switch {
+	case newMoonDay(k+1) <= dayNumber:
+		return k + 1
+	case newMoonDay(k) > dayNumber:
+		return k - 1
+	}
+	return k
+}
+
+// monthNames là tên gọi các tháng âm lịch
+var monthNames = []string{"", "Giêng", "Hai", "Ba", "Tư", "Năm", "Sáu", "Bảy", "Tám", "Chín", "Mười", "Một", "Chạp"}
+
+// MonthName trả về tên tháng âm lịch, ví dụ "Giêng" hay "Chạp"
+func MonthName(month int) string {
+	if month < 1 || month > 12 {
+		return fmt.Sprint(month)
+	}
+	return monthNames[month]
+}
+
+// DayName trả về tên ngày âm lịch: "mùng 1" đến "mùng 10", "rằm" cho ngày 15
+func DayName(day int) string {
+	switch {
+	case day >= 1 && day <= 10:
+		return fmt.Sprintf("mùng %d", day)
+	case day == 15:
+		return "rằm"
+	}
+	return fmt.Sprint(day)
+}
+
+// can và chi dùng để gọi tên năm âm lịch
+var (
+	can = []string{"Giáp", "Ất", "Bính", "Đinh", "Mậu", "Kỷ", "Canh", "Tân", "Nhâm", "Quý"}
+	chi = []string{"Tý", "Sửu", "Dần", "Mão", "Thìn", "Tỵ", "Ngọ", "Mùi", "Thân", "Dậu", "Tuất", "Hợi"}
+)
+
+// YearName trả về tên can chi của năm âm lịch, ví dụ "Bính Ngọ"
+func YearName(year int) string {
+	return can[((year+6)%10+10)%10] + " " + chi[((year+8)%12+12)%12]
+}
+
+// String trả về ngày dạng ngắn, ví dụ "15/8" hoặc "3/4N" với tháng nhuận
+func (d Date) String() string {
+	if d.Leap {
+		return fmt.Sprintf("%d/%dN", d.Day, d.Month)
+	}
+	return fmt.Sprintf("%d/%d", d.Day, d.Month)
+}
+
+// Describe trả về ngày bằng lời, ví dụ "rằm tháng Tám" hay
+// "mùng 1 tháng Tư nhuận"
+func (d Date) Describe() string {
+	text := DayName(d.Day) + " tháng " + MonthName(d.Month)
+	if d.Leap {
+		text += " nhuận"
+	}
+	return text
+}
+
+// monthStart trả về số ngày Julius của mùng 1 tháng âm lịch
+func monthStart(year, month int, leap bool) (int, bool) {
+	var a11, b11 int
+	if month < 11 {
+		a11 = lunarMonth11(year - 1)
+		b11 = lunarMonth11(year)
+	} else {
+		a11 = lunarMonth11(year)
+		b11 = lunarMonth11(year + 1)
+	}
+
+	k := int(math.Floor(0.5 + (float64(a11)-2415021.076998695)/synodicMonth))
+	off := month - 11
+	if off < 0 {
+		off += 12
+	}
+
+	if b11-a11 > 365 {
+		leapOff := leapMonthOffset(a11)
+		if leap && month != leapMonthNumber(leapOff) {
+			return 0, false
+		}
+		if leap || off >= leapOff {
+			off++
+		}
+	} else if leap {
+		return 0, false
+	}
+
+	return newMoonDay(k + off), true
+}
+
+// solarToLunar đổi ngày dương lịch sang âm lịch
+func solarToLunar(dd, mm, yy int) (day, month, year int, leap bool) {
+	dayNumber := jdFromDate(dd, mm, yy)
+	start := newMoonDay(newMoonIndex(dayNumber))
+
+	a11 := lunarMonth11(yy)
+	b11 := a11
+	if a11 >= start {
+		year = yy
+		a11 = lunarMonth11(yy - 1)
+	} else {
+		year = yy + 1
+		b11 = lunarMonth11(yy + 1)
+	}
+
+	day = dayNumber - start + 1
+	diff := (start - a11) / 29
+	month = diff + 11
+	if b11-a11 > 365 {
+		leapDiff := leapMonthOffset(a11)
+		if diff >= leapDiff {
+			month = diff + 10
+			leap = diff == leapDiff
+		}
+	}
+	if month > 12 {
+		month -= 12
+	}
+	if month >= 11 && diff < 4 {
+		year--
+	}
+	return day, month, year, leap
+}
+
+// jdFromDate trả về số ngày Julius của một ngày dương lịch
+func jdFromDate(dd, mm, yy int) int {
+	a := (14 - mm) / 12
+	y := yy + 4800 - a
+	m := mm + 12*a - 3
+	jd := dd + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+	if jd < 2299161 {
+		jd = dd + (153*m+2)/5 + 365*y + y/4 - 32083
+	}
+	return jd
+}
+
+// jdToDate đổi số ngày Julius sang ngày dương lịch
+func jdToDate(jd int) (day, month, year int) {
+	var b, c int
+	if jd > 2299160 {
+		a := jd + 32044
+		b = (4*a + 3) / 146097
+		c = a - (b*146097)/4
+	} else {
+		c = jd + 32082
+	}
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+	day = e - (153*m+2)/5 + 1
+	month = m + 3 - 12*(m/10)
+	year = b*100 + d - 4800 + m/10
+	return day, month, year
+}
+
+// newMoon trả về thời điểm (ngày Julius) của điểm sóc thứ k kể từ
+// 1/1/1900
+func newMoon(k int) float64 {
+	t := float64(k) / 1236.85
+	t2 := t * t
+	t3 := t2 * t
+	dr := math.Pi / 180
+
+	jd1 := 2415020.75933 + 29.53058868*float64(k) + 0.0001178*t2 - 0.000000155*t3
+	jd1 += 0.00033 * math.Sin((166.56+132.87*t-0.009173*t2)*dr)
+	m := 359.2242 + 29.10535608*float64(k) - 0.0000333*t2 - 0.00000347*t3
+	mpr := 306.0253 + 385.81691806*float64(k) + 0.0107306*t2 + 0.00001236*t3
+	f := 21.2964 + 390.67050646*float64(k) - 0.0016528*t2 - 0.00000239*t3
+
+	c1 := (0.1734-0.000393*t)*math.Sin(m*dr) + 0.0021*math.Sin(2*dr*m)
+	c1 = c1 - 0.4068*math.Sin(mpr*dr) + 0.0161*math.Sin(dr*2*mpr)
+	c1 = c1 - 0.0004*math.Sin(dr*3*mpr)
+	c1 = c1 + 0.0104*math.Sin(dr*2*f) - 0.0051*math.Sin(dr*(m+mpr))
+	c1 = c1 - 0.0074*math.Sin(dr*(m-mpr)) + 0.0004*math.Sin(dr*(2*f+m))
+	c1 = c1 - 0.0004*math.Sin(dr*(2*f-m)) - 0.0006*math.Sin(dr*(2*f+mpr))
+	c1 = c1 + 0.0010*math.Sin(dr*(2*f-mpr)) + 0.0005*math.Sin(dr*(2*mpr+m))
+
+	var deltaT float64
+	if t < -11 {
+		deltaT = 0.001 + 0.000839*t + 0.0002261*t2 - 0.00000845*t3 - 0.000000081*t*t3
+	} else {
+		deltaT = -0.000278 + 0.000265*t + 0.000262*t2
+	}
+	return jd1 + c1 - deltaT
+}
+
+// sunLongitude trả về kinh độ mặt trời (radian) tại thời điểm jdn
+func sunLongitude(jdn float64) float64 {
+	t := (jdn - 2451545.0) / 36525
+	t2 := t * t
+	dr := math.Pi / 180
+
+	m := 357.52910 + 35999.05030*t - 0.0001559*t2 - 0.00000048*t*t2
+	l0 := 280.46645 + 36000.76983*t + 0.0003032*t2
+	dl := (1.914600 - 0.004817*t - 0.000014*t2) * math.Sin(dr*m)
+	dl += (0.019993-0.000101*t)*math.Sin(dr*2*m) + 0.000290*math.Sin(dr*3*m)
+
+	l := (l0 + dl) * dr
+	return l - math.Pi*2*math.Floor(l/(math.Pi*2))
+}
+
+// newMoonDay trả về số ngày Julius (theo giờ Việt Nam) của điểm sóc thứ k
+func newMoonDay(k int) int {
+	return int(math.Floor(newMoon(k) + 0.5 + timeZone/24))
+}
+
+// sunLongitudeSector trả về cung (0-11) của kinh độ mặt trời vào đầu ngày
+// dayNumber; cung 9 chứa Đông chí
+func sunLongitudeSector(dayNumber int) int {
+	return int(math.Floor(sunLongitude(float64(dayNumber)-0.5-timeZone/24) / math.Pi * 6))
+}
+
+// lunarMonth11 trả về ngày bắt đầu tháng 11 âm lịch (tháng chứa Đông chí)
+// của năm dương lịch yy
+func lunarMonth11(yy int) int {
+	off := jdFromDate(31, 12, yy) - 2415021
+	k := int(math.Floor(float64(off) / synodicMonth))
+	nm := newMoonDay(k)
+	if sunLongitudeSector(nm) >= 9 {
+		nm = newMoonDay(k - 1)
+	}
+	return nm
+}
+
+// leapMonthOffset trả về vị trí của tháng nhuận tính từ tháng 11 bắt đầu
+// ở ngày a11
+func leapMonthOffset(a11 int) int {
+	k := int(math.Floor((float64(a11)-2415021.076998695)/synodicMonth + 0.5))
+	i := 1
+	arc := sunLongitudeSector(newMoonDay(k + i))
+	for {
+		last := arc
+		i++
+		arc = sunLongitudeSector(newMoonDay(k + i))
+		if arc == last || i >= 14 {
+			break
+		}
+	}
+	return i - 1
+}
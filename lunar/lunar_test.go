@@ -0,0 +1,67 @@
+package lunar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromSolar(t *testing.T) {
+	tests := []struct {
+		solar time.Time
+		want  Date
+	}{
+		// Tết Nguyên đán
+		{time.Date(2024, 2, 10, 0, 0, 0, 0, location), Date{Year: 2024, Month: 1, Day: 1}},
+		{time.Date(2025, 1, 29, 0, 0, 0, 0, location), Date{Year: 2025, Month: 1, Day: 1}},
+		// Việt Nam đón Tết sớm hơn Trung Quốc một tháng
+		{time.Date(1985, 1, 21, 0, 0, 0, 0, location), Date{Year: 1985, Month: 1, Day: 1}},
+		{time.Date(1985, 2, 20, 0, 0, 0, 0, location), Date{Year: 1985, Month: 2, Day: 1}},
+		// Tháng nhuận
+		{time.Date(2004, 3, 21, 0, 0, 0, 0, location), Date{Year: 2004, Month: 2, Day: 1, Leap: true}},
+		{time.Date(2023, 3, 22, 0, 0, 0, 0, location), Date{Year: 2023, Month: 2, Day: 1, Leap: true}},
+		// Ngày mà ước lượng theo tháng trung bình rơi vào tháng sau
+		{time.Date(2054, 5, 7, 0, 0, 0, 0, location), Date{Year: 2054, Month: 3, Day: 30}},
+		{time.Date(2054, 5, 8, 0, 0, 0, 0, location), Date{Year: 2054, Month: 4, Day: 1}},
+		{time.Date(2062, 4, 9, 0, 0, 0, 0, location), Date{Year: 2062, Month: 2, Day: 30}},
+		{time.Date(2062, 4, 10, 0, 0, 0, 0, location), Date{Year: 2062, Month: 3, Day: 1}},
+	}
+
+	for _, test := range tests {
+		if got := FromSolar(test.solar); got != test.want {
+			t.Errorf("FromSolar(%s) = %v, want %v", test.solar.Format(time.DateOnly), got, test.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	end := time.Date(2100, 12, 31, 0, 0, 0, 0, location)
+	for day := time.Date(1950, 1, 1, 0, 0, 0, 0, location); !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := FromSolar(day)
+		back, err := date.ToSolar()
+		if err != nil {
+			t.Fatalf("%s → %v: %v", day.Format(time.DateOnly), date, err)
+		}
+		if !back.Equal(day) {
+			t.Fatalf("%s → %v → %s", day.Format(time.DateOnly), date, back.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestMonthsBetween(t *testing.T) {
+	tests := []struct {
+		a, b time.Time
+		want int
+	}{
+		{time.Date(2024, 2, 10, 0, 0, 0, 0, location), time.Date(2024, 3, 9, 0, 0, 0, 0, location), 0},
+		{time.Date(2024, 2, 10, 0, 0, 0, 0, location), time.Date(2024, 3, 10, 0, 0, 0, 0, location), 1},
+		// Năm 2023 có tháng 2 nhuận
+		{time.Date(2023, 1, 22, 0, 0, 0, 0, location), time.Date(2024, 2, 10, 0, 0, 0, 0, location), 13},
+		{time.Date(2054, 5, 7, 0, 0, 0, 0, location), time.Date(2054, 5, 8, 0, 0, 0, 0, location), 1},
+	}
+
+	for _, test := range tests {
+		if got := MonthsBetween(test.a, test.b); got != test.want {
+			t.Errorf("MonthsBetween(%s, %s) = %d, want %d", test.a.Format(time.DateOnly), test.b.Format(time.DateOnly), got, test.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -21,30 +24,27 @@ import (
 
 // TodoApp represents the main application structure
 type TodoApp struct {
+	config      *Config            // Persisted application settings
+	store       Store              // Storage backend for todos and projects
 	todoList    *TodoList          // Backend todo list for main todos
 	projectList *ProjectList       // Backend project list for selected project
 	window      fyne.Window        // Main application window
 	tabs        *container.AppTabs // Tab container
 	myApp       fyne.App           // Reference to the Fyne application
 	isDarkTheme bool               // Current theme state
+	undoStack   *UndoStack         // Undo/redo history of user actions
+	snackbar    *Snackbar          // Transient messages with an undo action
+	watcher     *dataWatcher       // Reloads lists changed by other programs
+	dataDir     string             // Absolute data root, see resolveDataDir
 
 	// Todo tab widgets
-	allList        *widget.List
-	activeList     *widget.List
-	completedList  *widget.List
-	allTodos       []Todo
-	activeTodos    []Todo
-	completedTodos []Todo
+	todoTab *listTab
 
 	// Project tab widgets
-	projectAllList        *widget.List
-	projectActiveList     *widget.List
-	projectCompletedList  *widget.List
-	projectAllTodos       []Todo
-	projectActiveTodos    []Todo
-	projectCompletedTodos []Todo
+	projectTab            *listTab
 	projectSelect         *widget.Select
 	projectTodoEntry      *widget.Entry
+	projectPrioritySelect *widget.Select
 	currentProject        string
 	projectColor          string
 	projectThemeInfo      *widget.Label
@@ -54,6 +54,21 @@ type TodoApp struct {
 func main() {
 	fmt.Println("🚀 Starting Todo App...")
 
+	dataDirFlag := flag.String("data-dir", "", "thư mục dữ liệu (mặc định $"+dataDirEnv+" hoặc $XDG_DATA_HOME/"+dataDirName+")")
+	flag.Parse()
+
+	dataDir, err := resolveDataDir(*dataDirFlag)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if from, err := migrateLegacyData(dataDir); err != nil {
+		fmt.Printf("⚠️ Lỗi chuyển dữ liệu cũ từ %s: %v\n", from, err)
+	} else if from != "" {
+		fmt.Printf("📦 Moved data from %s to %s\n", from, dataDir)
+	}
+	fmt.Printf("📂 Data directory: %s\n", dataDir)
+
 	// Force software rendering for better compatibility
 	os.Setenv("FYNE_DRIVER", "x11")
 	os.Setenv("FYNE_SOFTWARE", "1")
@@ -73,14 +88,35 @@ func main() {
 	myWindow.Resize(fyne.NewSize(900, 700))
 	myWindow.CenterOnScreen()
 
+	config := LoadConfig(dataDir)
+	store, err := NewStore(config.StorageBackend, dataDir)
+	if err != nil {
+		fmt.Printf("⚠️ %v, using text backend\n", err)
+		config.StorageBackend = BackendText
+		store, _ = NewStore(BackendText, dataDir)
+	}
+	fmt.Printf("💾 Storage backend: %s\n", config.StorageBackend)
+
 	todoApp := &TodoApp{
-		todoList:    NewTodoList("todos.txt"),
+		config:      config,
+		store:       store,
+		todoList:    NewTodoList(store, ""),
 		window:      myWindow,
 		myApp:       myApp,
 		isDarkTheme: false,
+		undoStack:   NewUndoStack(defaultUndoLimit),
+		snackbar:    NewSnackbar(myWindow),
+		dataDir:     dataDir,
 	}
 
+	// Fold pending journal entries into the snapshots on exit
+	myApp.Lifecycle().SetOnStopped(func() {
+		todoApp.stopWatching()
+		todoApp.compactLists()
+	})
+
 	todoApp.setupUI()
+	todoApp.startWatching()
 	myWindow.Show()
 	myApp.Run()
 }
@@ -93,6 +129,14 @@ func (app *TodoApp) setupUI() {
 	})
 	settingsButton.Importance = widget.MediumImportance
 
+	// Undo/redo buttons
+	undoButton := widget.NewButton("↶", func() {
+		app.undo()
+	})
+	redoButton := widget.NewButton("↷", func() {
+		app.redo()
+	})
+
 	// Setup tabs
 	todoTabContent := app.setupTodoTab()
 	projectTabContent := app.setupProjectTab()
@@ -107,20 +151,22 @@ func (app *TodoApp) setupUI() {
 	header := widget.NewCard("", "Todo List Desktop App", nil)
 	headerWithButtons := container.NewBorder(
 		nil, nil, nil,
-		container.NewHBox(settingsButton),
+		container.NewHBox(undoButton, redoButton, settingsButton),
 		header,
 	)
 
 	// Main view
 	mainView := container.NewBorder(
-		container.NewVBox(headerWithButtons, widget.NewSeparator()),
+		container.NewVBox(headerWithButtons, app.createSearchBar(), widget.NewSeparator()),
 		nil, nil, nil,
 		app.tabs,
 	)
 
 	app.window.SetContent(mainView)
+	app.setupShortcuts()
 
 	// Load initial data
+	app.purgeTrash(app.todoList)
 	app.refreshAllLists()
 	app.applyTheme()
 
@@ -134,43 +180,38 @@ func (app *TodoApp) setupUI() {
 
 // setupTodoTab creates the todos tab content
 func (app *TodoApp) setupTodoTab() *fyne.Container {
-	// Create lists
-	app.allList = app.createList("all", false)
-	app.activeList = app.createList("active", false)
-	app.completedList = app.createList("completed", false)
-
 	// Input for adding todos
 	todoEntry := widget.NewEntry()
-	todoEntry.SetPlaceHolder("Nhập công việc mới...")
+	todoEntry.SetPlaceHolder("Nhập công việc mới... (#thẻ để gắn thẻ)")
+
+	// Priority of new todos
+	prioritySelect := newPrioritySelect()
 
 	addTodoBtn := widget.NewButton("+ Thêm Todo", func() {
-		app.addTodo(todoEntry.Text, false)
+		app.addTodo(todoEntry.Text, ParsePriorityLabel(prioritySelect.Selected), false)
 		todoEntry.SetText("")
 	})
 	addTodoBtn.Importance = widget.HighImportance
 
 	// Enter key support
 	todoEntry.OnSubmitted = func(text string) {
-		app.addTodo(text, false)
+		app.addTodo(text, ParsePriorityLabel(prioritySelect.Selected), false)
 		todoEntry.SetText("")
 	}
 
-	todoInputContainer := container.NewBorder(nil, nil, nil, addTodoBtn, todoEntry)
+	todoInputContainer := container.NewBorder(nil, nil, nil, container.NewHBox(prioritySelect, addTodoBtn), todoEntry)
 
 	// Todo sub-tabs
-	todoSubTabs := container.NewAppTabs(
-		container.NewTabItem("Tất cả", container.NewScroll(app.allList)),
-		container.NewTabItem("Chưa hoàn thành", container.NewScroll(app.activeList)),
-		container.NewTabItem("Đã hoàn thành", container.NewScroll(app.completedList)),
-	)
+	todoSubTabs := app.createSubTabs(false)
 
 	// Main container
 	return container.NewBorder(
 		container.NewVBox(
-			widget.NewLabel("📋 Quản lý Todos"),
+			container.NewBorder(nil, nil, nil, app.createTransferButton(false), widget.NewLabel("📋 Quản lý Todos")),
 			widget.NewSeparator(),
 			todoInputContainer,
 			widget.NewSeparator(),
+			app.createTagFilterBar(false),
 		),
 		nil, nil, nil,
 		todoSubTabs,
@@ -181,7 +222,7 @@ func (app *TodoApp) setupTodoTab() *fyne.Container {
 func (app *TodoApp) setupProjectTab() *fyne.Container {
 	// Project selection
 	app.projectSelect = widget.NewSelect([]string{}, func(selected string) {
-		if selected != "" && selected != "Chưa có project nào" {
+		if selected != "" && selected != noProjectsOption {
 			app.loadProject(selected)
 		}
 	})
@@ -202,19 +243,35 @@ func (app *TodoApp) setupProjectTab() *fyne.Container {
 	})
 	projectSettingsBtn.Importance = widget.MediumImportance
 
-	projectButtons := container.NewHBox(projectSettingsBtn, addProjectBtn)
+	// Project restore button
+	projectRestoreBtn := widget.NewButton("♻️ Khôi phục", func() {
+		if app.projectList == nil || app.currentProject == "" {
+			dialog.ShowInformation("Thông báo", "Chọn project trước khi khôi phục", app.window)
+			return
+		}
+		app.showRestoreBackupDialog(app.projectList.TodoList, app.currentProject)
+	})
+
+	// Delete project button
+	deleteProjectBtn := widget.NewButton("🗑️", func() {
+		app.confirmDeleteProject()
+	})
+	deleteProjectBtn.Importance = widget.DangerImportance
+
+	projectButtons := container.NewHBox(projectSettingsBtn, projectRestoreBtn, app.createTransferButton(true), deleteProjectBtn, addProjectBtn)
 	projectSelector := container.NewBorder(nil, nil, nil, projectButtons, app.projectSelect)
 
 	// Project todo input
 	app.projectTodoEntry = widget.NewEntry()
 	app.projectTodoEntry.SetPlaceHolder("Nhập công việc cho project...")
+	app.projectPrioritySelect = newPrioritySelect()
 
 	addProjectTodoBtn := widget.NewButton("+ Thêm", func() {
 		if app.currentProject == "" {
 			dialog.ShowInformation("Thông báo", "Chọn project trước khi thêm todo", app.window)
 			return
 		}
-		app.addTodo(app.projectTodoEntry.Text, true)
+		app.addTodo(app.projectTodoEntry.Text, app.projectPriority(), true)
 		app.projectTodoEntry.SetText("")
 	})
 	addProjectTodoBtn.Importance = widget.HighImportance
@@ -225,29 +282,14 @@ func (app *TodoApp) setupProjectTab() *fyne.Container {
 			dialog.ShowInformation("Thông báo", "Chọn project trước khi thêm todo", app.window)
 			return
 		}
-		app.addTodo(text, true)
+		app.addTodo(text, app.projectPriority(), true)
 		app.projectTodoEntry.SetText("")
 	}
 
-	projectTodoInputContainer := container.NewBorder(nil, nil, nil, addProjectTodoBtn, app.projectTodoEntry)
-
-	// Project todo lists - create if not exists
-	if app.projectAllList == nil {
-		app.projectAllList = app.createList("all", true)
-	}
-	if app.projectActiveList == nil {
-		app.projectActiveList = app.createList("active", true)
-	}
-	if app.projectCompletedList == nil {
-		app.projectCompletedList = app.createList("completed", true)
-	}
+	projectTodoInputContainer := container.NewBorder(nil, nil, nil, container.NewHBox(app.projectPrioritySelect, addProjectTodoBtn), app.projectTodoEntry)
 
 	// Project sub-tabs
-	projectSubTabs := container.NewAppTabs(
-		container.NewTabItem("Tất cả", container.NewScroll(app.projectAllList)),
-		container.NewTabItem("Chưa hoàn thành", container.NewScroll(app.projectActiveList)),
-		container.NewTabItem("Đã hoàn thành", container.NewScroll(app.projectCompletedList)),
-	)
+	projectSubTabs := app.createSubTabs(true)
 
 	// Load available projects
 	app.refreshProjectList()
@@ -269,6 +311,7 @@ func (app *TodoApp) setupProjectTab() *fyne.Container {
 			widget.NewSeparator(),
 			projectTodoInputContainer,
 			widget.NewSeparator(),
+			app.createTagFilterBar(true),
 		),
 		nil, nil, nil,
 		projectSubTabs,
@@ -276,86 +319,83 @@ func (app *TodoApp) setupProjectTab() *fyne.Container {
 }
 
 // createList creates a todo list widget
-func (app *TodoApp) createList(listType string, isProject bool) *widget.List {
-	list := widget.NewList(
-		func() int {
-			if isProject {
-				switch listType {
-				case "all":
-					return len(app.projectAllTodos)
-				case "active":
-					return len(app.projectActiveTodos)
-				case "completed":
-					return len(app.projectCompletedTodos)
-				}
-			} else {
-				switch listType {
-				case "all":
-					return len(app.allTodos)
-				case "active":
-					return len(app.activeTodos)
-				case "completed":
-					return len(app.completedTodos)
-				}
-			}
-			return 0
-		},
-		func() fyne.CanvasObject {
-			return widget.NewCard("", "", widget.NewLabel(""))
-		},
-		func(id widget.ListItemID, item fyne.CanvasObject) {
-			app.updateTodoItem(id, item, listType, isProject)
-		},
-	)
+func (app *TodoApp) createList(listType string, isProject bool) *todoListWidget {
+	list := &todoListWidget{}
+	list.Length = func() int {
+		return len(app.getListTodos(listType, isProject))
+	}
+	list.CreateItem = func() fyne.CanvasObject {
+		return widget.NewCard("", "", widget.NewLabel(""))
+	}
+	list.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {
+		app.updateTodoItem(id, item, listType, isProject)
+	}
+	list.ExtendBaseWidget(list)
 
 	list.OnSelected = func(id widget.ListItemID) {
+		if todo, ok := app.todoAt(id, listType, isProject); ok {
+			tab := app.listTab(isProject)
+			tab.selectedID = todo.ID
+			tab.selectedIn = listType
+		}
 		app.handleTodoSelection(id, listType, isProject)
 	}
+	list.onEdit = func() {
+		app.editSelectedTodo(isProject)
+	}
 
 	return list
 }
 
+// getListTodos returns the todos currently shown by a list widget
+func (app *TodoApp) getListTodos(listType string, isProject bool) []Todo {
+	if view := app.views(isProject)[listType]; view != nil {
+		return view.todos
+	}
+	return nil
+}
+
+// todoAt returns the todo displayed at a row of a list widget. The todos of
+// a view are already in display order (see refreshViews).
+func (app *TodoApp) todoAt(id widget.ListItemID, listType string, isProject bool) (Todo, bool) {
+	todos := app.getListTodos(listType, isProject)
+	if id < 0 || id >= len(todos) {
+		return Todo{}, false
+	}
+	return todos[id], true
+}
+
 // updateTodoItem updates a todo item in the list
 func (app *TodoApp) updateTodoItem(id widget.ListItemID, item fyne.CanvasObject, listType string, isProject bool) {
-	var todos []Todo
-
-	if isProject {
-		switch listType {
-		case "all":
-			todos = app.projectAllTodos
-		case "active":
-			todos = app.projectActiveTodos
-		case "completed":
-			todos = app.projectCompletedTodos
-		}
-	} else {
-		switch listType {
-		case "all":
-			todos = app.allTodos
-		case "active":
-			todos = app.activeTodos
-		case "completed":
-			todos = app.completedTodos
-		}
+	todo, ok := app.todoAt(id, listType, isProject)
+	if !ok {
+		return
 	}
+	card := item.(*widget.Card)
 
-	if id >= len(todos) {
+	if listType == "trash" {
+		app.updateTrashItem(todo, card, isProject)
 		return
 	}
 
-	// Newest first
-	reversedIndex := len(todos) - 1 - id
-	todo := todos[reversedIndex]
-	card := item.(*widget.Card)
+	tab := app.listTab(isProject)
+	if tab.editingID == todo.ID && tab.editingIn == listType {
+		app.updateInlineEditItem(card, isProject)
+		return
+	}
 
 	// Date label
-	dateLabel := widget.NewLabel(todo.CreatedAt.Format("02/01 15:04"))
+	dateLabel := widget.NewLabel(todo.CreatedAt.Format("02/01 15:04") + app.lunarSuffix(todo.CreatedAt))
 	dateLabel.TextStyle = fyne.TextStyle{Italic: true}
 
-	// Content label
-	contentLabel := widget.NewLabel(todo.Description)
-	contentLabel.TextStyle = fyne.TextStyle{Bold: true}
-	contentLabel.Wrapping = fyne.TextWrapWord
+	// Content label; a double-click edits the description in place
+	contentLabel := newTodoLabel(todo.Description, func() {
+		if view := tab.views[listType]; view != nil {
+			view.list.Select(id)
+		}
+	}, func() {
+		app.startInlineEdit(todo.ID, listType, isProject)
+	})
 
 	// Complete checkbox
 	var completeCheck *widget.Check
@@ -363,154 +403,239 @@ func (app *TodoApp) updateTodoItem(id widget.ListItemID, item fyne.CanvasObject,
 		if !todo.Completed && checked {
 			app.markComplete(todo.ID, isProject)
 		} else if todo.Completed && !checked {
-			dialog.ShowInformation("Thông báo", "Không thể bỏ tích công việc đã hoàn thành", app.window)
-			completeCheck.SetChecked(true)
+			app.markIncomplete(todo.ID, isProject)
 		}
 	})
 	completeCheck.SetChecked(todo.Completed)
 
 	// Delete button
 	deleteBtn := widget.NewButton("🗑️", func() {
-		app.confirmDelete(todo.ID, todo.Description, isProject)
+		app.deleteTodo(todo.ID, isProject)
 	})
 
 	buttonsContainer := container.NewHBox(completeCheck, deleteBtn)
 
-	// Layout
+	// Subtask progress, start and due dates and tags below the description
+	var content fyne.CanvasObject = contentLabel
+	details := container.NewVBox(contentLabel)
+	if progressLabel := app.createProgressLabel(todo, isProject); progressLabel != nil {
+		details.Add(progressLabel)
+	}
+	if dueLabel := app.createDueLabel(todo); dueLabel != nil {
+		details.Add(dueLabel)
+	}
+	if todo.Completed && !todo.CompletedAt.IsZero() {
+		doneLabel := widget.NewLabel("✅ Hoàn thành: " + todo.CompletedAt.Format("02/01/2006 15:04") + app.lunarSuffix(todo.CompletedAt))
+		doneLabel.Importance = widget.SuccessImportance
+		details.Add(doneLabel)
+	}
+	if !todo.Recurrence.IsZero() {
+		repeatLabel := widget.NewLabel("🔁 " + todo.Recurrence.Describe())
+		repeatLabel.TextStyle = fyne.TextStyle{Italic: true}
+		details.Add(repeatLabel)
+	}
+	if chips := app.createTagChips(todo, isProject); chips != nil {
+		details.Add(chips)
+	}
+	if len(details.Objects) > 1 {
+		content = details
+	}
+
+	// Layout; the drag handle is only shown in the manually ordered list
+	left := container.NewHBox(app.createSubtaskToggle(todo, listType, id, isProject), createPriorityMarker(todo.Priority), dateLabel)
+	if handle := app.createDragHandle(id, card, listType, isProject); handle != nil {
+		left.Objects = append([]fyne.CanvasObject{handle}, left.Objects...)
+	}
 	horizontalLayout := container.NewBorder(
 		nil, nil,
-		dateLabel,
+		left,
 		buttonsContainer,
-		contentLabel,
+		content,
 	)
 
 	card.SetContent(container.NewPadded(horizontalLayout))
 }
 
 // addTodo adds a new todo item
-func (app *TodoApp) addTodo(description string, isProject bool) {
-	description = strings.TrimSpace(description)
+func (app *TodoApp) addTodo(description string, priority Priority, isProject bool) {
+	app.addTodoItem(Todo{Description: description, Priority: priority}, isProject)
+}
+
+// addTodoItem adds a new todo with the optional fields set on todo
+func (app *TodoApp) addTodoItem(todo Todo, isProject bool) {
+	description := strings.TrimSpace(todo.Description)
 	if description == "" {
 		dialog.ShowError(fmt.Errorf("vui lòng nhập mô tả công việc"), app.window)
 		return
 	}
 
-	var err error
-	if isProject && app.projectList != nil {
-		err = app.projectList.AddTodo(description)
-	} else if !isProject {
-		err = app.todoList.AddTodo(description)
+	if isProject && app.projectList == nil {
+		return
 	}
 
+	// Inline tags such as "#work" become tags of the todo
+	description, tags := ParseTags(description)
+	if description == "" {
+		dialog.ShowError(fmt.Errorf("vui lòng nhập mô tả công việc"), app.window)
+		return
+	}
+	todo.Description = description
+	todo.Tags = normalizeTags(append(todo.Tags, tags...))
+
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+	added, err := list.AddTodoItem(todo)
 	if err != nil {
-		dialog.ShowError(err, app.window)
+		app.showListError(err)
 		return
 	}
 
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Thêm: %s", description),
+		Undo: func() error {
+			return app.listFor(project).RemoveTodo(added.ID)
+		},
+		Redo: func() error {
+			return app.listFor(project).PutTodo(added)
+		},
+	})
+
 	app.refreshAllLists()
 	dialog.ShowInformation("Thành công", fmt.Sprintf("Đã thêm: %s", description), app.window)
 }
 
 // markComplete marks a todo as completed
 func (app *TodoApp) markComplete(todoID int, isProject bool) {
-	var err error
-	var todoDescription string
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
 
-	// Find todo description
-	var todos []Todo
-	if isProject && app.projectList != nil {
-		todos = app.projectList.GetTodos()
-	} else {
-		todos = app.todoList.GetTodos()
+	// Offer to complete the unfinished subtasks as well
+	if incomplete := incompleteDescendants(list, todoID); len(incomplete) > 0 {
+		app.confirmCompleteParent(todoID, len(incomplete), isProject)
+		return
 	}
 
-	for _, todo := range todos {
-		if todo.ID == todoID {
-			todoDescription = todo.Description
-			break
-		}
+	// Keep the previous state for undo
+	previous, _ := list.GetTodo(todoID)
+
+	// A repeating todo creates its next occurrence, which undo removes
+	next, repeated, err := list.MarkCompleteRepeat(todoID)
+	if err != nil {
+		app.showListError(err)
+		return
 	}
 
-	// Mark complete
-	if isProject && app.projectList != nil {
-		err = app.projectList.MarkComplete(todoID)
-	} else {
-		err = app.todoList.MarkComplete(todoID)
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Hoàn thành: %s", previous.Description),
+		Undo: func() error {
+			list := app.listFor(project)
+			if repeated {
+				if err := list.RemoveTodo(next.ID); err != nil {
+					return err
+				}
+			}
+			return list.PutTodo(previous)
+		},
+		Redo: func() error {
+			var err error
+			next, repeated, err = app.listFor(project).MarkCompleteRepeat(todoID)
+			return err
+		},
+	})
+
+	app.refreshAllLists()
+	fireworks.ShowFireworksDialog(previous.Description, app.window)
+}
+
+// markIncomplete reopens a completed todo
+func (app *TodoApp) markIncomplete(todoID int, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	// Keep the previous state for undo
+	previous, ok := list.GetTodo(todoID)
+	if !ok {
+		dialog.ShowError(fmt.Errorf("không tìm thấy công việc với ID %d", todoID), app.window)
+		return
 	}
 
-	if err != nil {
-		dialog.ShowError(err, app.window)
+	if err := list.MarkIncomplete(todoID); err != nil {
+		app.showListError(err)
 		return
 	}
 
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Mở lại: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).MarkIncomplete(todoID)
+		},
+	})
+
 	app.refreshAllLists()
-	fireworks.ShowFireworksDialog(todoDescription, app.window)
+	app.showUndoSnackbar(fmt.Sprintf("Đã mở lại: %s", previous.Description))
 }
 
-// confirmDelete shows confirmation dialog for deleting todo
-func (app *TodoApp) confirmDelete(todoID int, description string, isProject bool) {
-	dialog.ShowConfirm("Xác nhận xóa",
-		fmt.Sprintf("Bạn có chắc chắn muốn xóa:\n'%s'?", description),
-		func(confirmed bool) {
-			if confirmed {
-				var err error
-				if isProject && app.projectList != nil {
-					err = app.projectList.DeleteTodo(todoID)
-				} else {
-					err = app.todoList.DeleteTodo(todoID)
-				}
+// deleteTodo deletes a todo right away and offers to undo it
+func (app *TodoApp) deleteTodo(todoID int, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
 
-				if err != nil {
-					dialog.ShowError(err, app.window)
-					return
-				}
+	deleted, ok := list.GetTodo(todoID)
+	if !ok {
+		dialog.ShowError(fmt.Errorf("không tìm thấy công việc với ID %d", todoID), app.window)
+		return
+	}
 
-				app.refreshAllLists()
-				dialog.ShowInformation("Thành công", fmt.Sprintf("Đã xóa: %s", description), app.window)
-			}
-		}, app.window)
+	// Ask what happens to the subtasks
+	if len(list.Children(todoID)) > 0 {
+		app.confirmDeleteParent(deleted, isProject)
+		return
+	}
+
+	if err := list.DeleteTodo(todoID); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Xóa: %s", deleted.Description),
+		Undo: func() error {
+			return app.listFor(project).RestoreTodo(todoID)
+		},
+		Redo: func() error {
+			return app.listFor(project).DeleteTodo(todoID)
+		},
+	})
+
+	app.refreshAllLists()
+	app.showUndoSnackbar(fmt.Sprintf("Đã chuyển vào thùng rác: %s", deleted.Description))
 }
 
 // handleTodoSelection handles when a todo is selected
 func (app *TodoApp) handleTodoSelection(id widget.ListItemID, listType string, isProject bool) {
-	var todos []Todo
-
-	if isProject {
-		switch listType {
-		case "all":
-			todos = app.projectAllTodos
-		case "active":
-			todos = app.projectActiveTodos
-		case "completed":
-			todos = app.projectCompletedTodos
-		}
-	} else {
-		switch listType {
-		case "all":
-			todos = app.allTodos
-		case "active":
-			todos = app.activeTodos
-		case "completed":
-			todos = app.completedTodos
-		}
+	todo, ok := app.todoAt(id, listType, isProject)
+	if !ok {
+		return
 	}
 
-	if id >= len(todos) {
+	if listType == "trash" {
+		app.showTrashItemDialog(todo, isProject)
 		return
 	}
 
-	reversedIndex := len(todos) - 1 - id
-	todo := todos[reversedIndex]
-
 	if todo.Completed {
-		dialog.ShowConfirm("Công việc đã hoàn thành",
-			fmt.Sprintf("Công việc: %s\nBạn muốn xóa?", todo.Description),
-			func(confirmed bool) {
-				if confirmed {
-					app.confirmDelete(todo.ID, todo.Description, isProject)
-				}
-			}, app.window)
+		app.showCompletedTodoDialog(todo, listType, isProject)
 	} else {
+		var d dialog.Dialog
+
+		editBtn := widget.NewButton("✏️ Sửa", func() {
+			d.Hide()
+			app.startInlineEdit(todo.ID, listType, isProject)
+		})
+
 		// Show options
 		completeBtn := widget.NewButton("✅ Đánh dấu hoàn thành", func() {
 			app.markComplete(todo.ID, isProject)
@@ -518,151 +643,375 @@ func (app *TodoApp) handleTodoSelection(id widget.ListItemID, listType string, i
 		completeBtn.Importance = widget.SuccessImportance
 
 		deleteBtn := widget.NewButton("🗑️ Xóa", func() {
-			app.confirmDelete(todo.ID, todo.Description, isProject)
+			app.deleteTodo(todo.ID, isProject)
 		})
 		deleteBtn.Importance = widget.DangerImportance
 
+		datesBtn := widget.NewButton("📅 Ngày", func() {
+			app.showDatesDialog(todo, isProject)
+		})
+
+		priorityBtn := widget.NewButton("⭐ Độ ưu tiên", func() {
+			app.showPriorityDialog(todo, isProject)
+		})
+
+		tagsBtn := widget.NewButton("🏷️ Thẻ", func() {
+			app.showTagsDialog(todo, isProject)
+		})
+
+		repeatBtn := widget.NewButton("🔁 Lặp lại", func() {
+			app.showRecurrenceDialog(todo, isProject)
+		})
+
+		subtaskBtn := widget.NewButton("➕ Thêm việc con", func() {
+			app.showAddSubtaskDialog(todo, isProject)
+		})
+
+		historyBtn := widget.NewButton("📜 Lịch sử", func() {
+			app.showTodoHistory(todo, isProject)
+		})
+
 		content := container.NewVBox(
 			widget.NewLabel(fmt.Sprintf("Công việc: %s", todo.Description)),
 			widget.NewSeparator(),
 			completeBtn,
+			editBtn,
+			datesBtn,
+			priorityBtn,
+			tagsBtn,
+			repeatBtn,
+			subtaskBtn,
 			deleteBtn,
+			historyBtn,
 		)
 
-		dialog.ShowCustom("Chọn hành động", "Hủy", content, app.window)
+		d = dialog.NewCustom("Chọn hành động", "Hủy", content, app.window)
+		d.Show()
 	}
 }
 
-// refreshAllLists refreshes all todo lists
-func (app *TodoApp) refreshAllLists() {
-	// Main todos
-	app.allTodos = app.todoList.GetTodos()
-	app.activeTodos = app.todoList.GetActiveTodos()
-	app.completedTodos = app.todoList.GetCompletedTodos()
+// showCompletedTodoDialog shows the actions for a completed todo
+func (app *TodoApp) showCompletedTodoDialog(todo Todo, listType string, isProject bool) {
+	var d dialog.Dialog
+
+	editBtn := widget.NewButton("✏️ Sửa", func() {
+		d.Hide()
+		app.startInlineEdit(todo.ID, listType, isProject)
+	})
+
+	reopenBtn := widget.NewButton("↩️ Mở lại", func() {
+		d.Hide()
+		app.markIncomplete(todo.ID, isProject)
+	})
+	reopenBtn.Importance = widget.HighImportance
+
+	deleteBtn := widget.NewButton("🗑️ Xóa", func() {
+		d.Hide()
+		app.deleteTodo(todo.ID, isProject)
+	})
+	deleteBtn.Importance = widget.DangerImportance
+
+	historyBtn := widget.NewButton("📜 Lịch sử", func() {
+		app.showTodoHistory(todo, isProject)
+	})
 
-	if app.allList != nil {
-		app.allList.Refresh()
+	content := container.NewVBox(widget.NewLabel(fmt.Sprintf("Công việc: %s", todo.Description)))
+	if !todo.CompletedAt.IsZero() {
+		content.Add(widget.NewLabel("Hoàn thành lúc: " + todo.CompletedAt.Format("02/01/2006 15:04")))
 	}
-	if app.activeList != nil {
-		app.activeList.Refresh()
+	content.Add(widget.NewSeparator())
+	content.Add(reopenBtn)
+	content.Add(editBtn)
+	content.Add(deleteBtn)
+	content.Add(historyBtn)
+
+	d = dialog.NewCustom("Công việc đã hoàn thành", "Hủy", content, app.window)
+	d.Show()
+}
+
+// projectPriority returns the priority selected for new project todos
+func (app *TodoApp) projectPriority() Priority {
+	if app.projectPrioritySelect == nil {
+		return PriorityNone
 	}
-	if app.completedList != nil {
-		app.completedList.Refresh()
+	return ParsePriorityLabel(app.projectPrioritySelect.Selected)
+}
+
+// projectKey returns the store key of the main list or the current project
+func (app *TodoApp) projectKey(isProject bool) string {
+	if isProject {
+		return app.currentProject
 	}
+	return ""
+}
 
-	// Project todos
-	if app.projectList != nil {
-		app.projectAllTodos = app.projectList.GetTodos()
-		app.projectActiveTodos = app.projectList.GetActiveTodos()
-		app.projectCompletedTodos = app.projectList.GetCompletedTodos()
+// listFor returns the list stored under a key. The loaded list is reused
+// when possible; otherwise the list is opened from the store, so that undo
+// still works after switching to another project.
+func (app *TodoApp) listFor(project string) *TodoList {
+	if project == "" {
+		return app.todoList
+	}
+	if app.projectList != nil && app.currentProject == project {
+		return app.projectList.TodoList
+	}
+	return NewTodoList(app.store, project)
+}
+
+// setupShortcuts registers the window-wide keyboard shortcuts
+func (app *TodoApp) setupShortcuts() {
+	canvas := app.window.Canvas()
+
+	// Ctrl+Z: undo
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(fyne.Shortcut) {
+		app.undo()
+	})
+
+	// Ctrl+Shift+Z: redo
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+	}, func(fyne.Shortcut) {
+		app.redo()
+	})
+
+	// Alt+Up / Alt+Down: move the selected todo in the "Tất cả" list
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyUp,
+		Modifier: fyne.KeyModifierAlt,
+	}, func(fyne.Shortcut) {
+		if app.tabs != nil {
+			app.moveSelectedTodo(-1, app.tabs.SelectedIndex() == 1)
+		}
+	})
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyDown,
+		Modifier: fyne.KeyModifierAlt,
+	}, func(fyne.Shortcut) {
+		if app.tabs != nil {
+			app.moveSelectedTodo(1, app.tabs.SelectedIndex() == 1)
+		}
+	})
 
-		if app.projectAllList != nil {
-			app.projectAllList.Refresh()
+	// F2: edit the selected todo in place. The list widgets handle F2
+	// themselves while they have the focus.
+	canvas.SetOnTypedKey(func(key *fyne.KeyEvent) {
+		if key.Name == fyne.KeyF2 && app.tabs != nil {
+			app.editSelectedTodo(app.tabs.SelectedIndex() == 1)
 		}
-		if app.projectActiveList != nil {
-			app.projectActiveList.Refresh()
+	})
+}
+
+// undo reverts the last action
+func (app *TodoApp) undo() {
+	if !app.undoStack.CanUndo() {
+		app.snackbar.Show("Không có thao tác nào để hoàn tác", "", nil)
+		return
+	}
+
+	cmd, err := app.undoStack.Undo()
+	if err != nil {
+		app.showListError(fmt.Errorf("không thể hoàn tác '%s': %w", cmd.Label, err))
+		return
+	}
+
+	app.refreshAllLists()
+	app.snackbar.Show(fmt.Sprintf("Đã hoàn tác: %s", cmd.Label), "↷ Làm lại", app.redo)
+}
+
+// redo performs the last undone action again
+func (app *TodoApp) redo() {
+	if !app.undoStack.CanRedo() {
+		app.snackbar.Show("Không có thao tác nào để làm lại", "", nil)
+		return
+	}
+
+	cmd, err := app.undoStack.Redo()
+	if err != nil {
+		app.showListError(fmt.Errorf("không thể làm lại '%s': %w", cmd.Label, err))
+		return
+	}
+
+	app.refreshAllLists()
+	app.snackbar.Show(fmt.Sprintf("Đã làm lại: %s", cmd.Label), "Hoàn tác", app.undo)
+}
+
+// showUndoSnackbar shows a message with a "Hoàn tác" action for the last
+// command
+func (app *TodoApp) showUndoSnackbar(message string) {
+	app.snackbar.Show(message, "Hoàn tác", app.undo)
+}
+
+// compactLists compacts the journals of the loaded lists into their snapshots
+func (app *TodoApp) compactLists() {
+	if err := app.todoList.Compact(); err != nil {
+		fmt.Printf("❌ Error compacting todos: %v\n", err)
+	}
+	if app.projectList != nil {
+		if err := app.projectList.Compact(); err != nil {
+			fmt.Printf("❌ Error compacting project %s: %v\n", app.currentProject, err)
+		}
+	}
+}
+
+// showTodoHistory shows the journal history of a todo
+func (app *TodoApp) showTodoHistory(todo Todo, isProject bool) {
+	list := app.todoList
+	if isProject && app.projectList != nil {
+		list = app.projectList.TodoList
+	}
+
+	history := list.History(todo.ID)
+	if len(history) == 0 && len(todo.Completions) == 0 {
+		dialog.ShowInformation("📜 Lịch sử", "Chưa có lịch sử cho công việc này", app.window)
+		return
+	}
+
+	content := container.NewVBox(widget.NewLabel(fmt.Sprintf("Công việc: %s", todo.Description)), widget.NewSeparator())
+	if len(todo.Completions) > 0 {
+		content.Add(widget.NewLabelWithStyle("Hoàn thành / mở lại", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, event := range todo.Completions {
+			content.Add(widget.NewLabel(fmt.Sprintf("%s • %s", event.At.Format("02/01/2006 15:04:05"), event.Label())))
 		}
-		if app.projectCompletedList != nil {
-			app.projectCompletedList.Refresh()
+		content.Add(widget.NewSeparator())
+	}
+	for _, op := range history {
+		content.Add(widget.NewLabel(fmt.Sprintf("%s • %s", op.At.Format("02/01/2006 15:04:05"), journalOpLabel(op))))
+	}
+
+	dialog.ShowCustom("📜 Lịch sử", "Đóng", container.NewVScroll(content), app.window)
+}
+
+// journalOpLabel returns a readable label for a journal operation
+func journalOpLabel(op JournalOp) string {
+	switch op.Op {
+	case OpAdd:
+		return "➕ Thêm"
+	case OpComplete:
+		return "✅ Hoàn thành"
+	case OpReopen:
+		return "↩️ Mở lại"
+	case OpDelete:
+		return "🗑️ Xóa"
+	case OpEdit:
+		if op.Todo != nil {
+			return fmt.Sprintf("✏️ Sửa: %s", op.Todo.Description)
 		}
+		return "✏️ Sửa"
+	default:
+		return op.Op
 	}
 }
 
+// showListError shows the error of an operation on a list. Changes that
+// conflict with changes on disk open the merge conflict dialog instead.
+func (app *TodoApp) showListError(err error) {
+	var conflict *MergeConflictError
+	if errors.As(err, &conflict) {
+		app.showMergeConflictDialog(conflict)
+		return
+	}
+	dialog.ShowError(err, app.window)
+}
+
+// refreshAllLists refreshes all todo lists
+func (app *TodoApp) refreshAllLists() {
+	// Main todos
+	app.refreshViews(false, app.todoList)
+
+	// Project todos; smart lists also show todos while no project is open
+	if app.projectList != nil {
+		app.refreshViews(true, app.projectList.TodoList)
+	} else if app.projectTab != nil {
+		app.refreshViews(true, nil)
+	}
+}
+
+// projectColors are the colors a project can have
+var projectColors = []string{"blue", "red", "green", "yellow", "orange", "purple", "brown", "black"}
+
+// noProjectsOption is shown in the project dropdown when there is no project
+const noProjectsOption = "Chưa có project nào"
+
 // refreshProjectList updates the project dropdown
 func (app *TodoApp) refreshProjectList() {
-	projectDir := "data/project"
-	files, err := os.ReadDir(projectDir)
+	projectInfos, err := app.store.ListProjects()
 	if err != nil {
-		app.projectSelect.Options = []string{"Chưa có project nào"}
-		app.projectSelect.Refresh()
-		return
+		fmt.Printf("❌ Error listing projects: %v\n", err)
 	}
 
+	// Projects are already sorted by modification time (newest first)
 	var projects []string
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".txt") {
-			projectName := strings.TrimSuffix(file.Name(), ".txt")
-			projects = append(projects, projectName)
-		}
+	for _, info := range projectInfos {
+		projects = append(projects, info.Name)
 	}
 
 	if len(projects) == 0 {
-		projects = []string{"Chưa có project nào"}
-	} else {
-		// Sort by modification time (newest first)
-		sort.Slice(projects, func(i, j int) bool {
-			pathI := filepath.Join(projectDir, projects[i]+".txt")
-			pathJ := filepath.Join(projectDir, projects[j]+".txt")
-			statI, _ := os.Stat(pathI)
-			statJ, _ := os.Stat(pathJ)
-			return statI.ModTime().After(statJ.ModTime())
-		})
+		projects = []string{noProjectsOption}
 	}
 
 	app.projectSelect.Options = projects
 	app.projectSelect.Refresh()
 
-	// Auto-select first project if available
-	if len(projects) > 0 && projects[0] != "Chưa có project nào" {
+	// Keep the current project, otherwise auto-select the first one
+	if app.currentProject != "" && slices.Contains(projects, app.currentProject) {
+		return
+	}
+	if projects[0] != noProjectsOption {
 		app.projectSelect.SetSelected(projects[0])
 	}
 }
 
 // loadProject loads the selected project
 func (app *TodoApp) loadProject(projectName string) {
-	app.currentProject = projectName
-	filename := fmt.Sprintf("data/project/%s.txt", projectName)
+	meta, err := app.store.LoadProject(projectName)
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
 
-	// Migrate old project file format if needed
-	app.migrateOldProjectFile(projectName)
+	// Fold the journal of the previous project into its snapshot
+	if app.projectList != nil {
+		if err := app.projectList.Compact(); err != nil {
+			fmt.Printf("❌ Error compacting project %s: %v\n", app.currentProject, err)
+		}
+	}
 
-	// Get project color, theme and background image
-	projectColor := app.getProjectColor(projectName)
-	backgroundImage := app.getProjectBackgroundImage(projectName)
-	app.projectColor = projectColor
+	app.currentProject = projectName
+	app.projectColor = meta.Color
+	projectTab := app.listTab(true)
+	projectTab.collapsed = make(map[int]bool)
+	projectTab.selectedID, projectTab.editingID = 0, 0
 
 	// Create ProjectList with color, theme and background image
-	app.projectList = NewProjectList(filename, projectName, projectColor, projectColor, backgroundImage)
+	app.projectList = NewProjectList(app.store, meta)
+	app.purgeTrash(app.projectList.TodoList)
 
 	// Refresh project lists and apply project theme
 	app.refreshAllLists()
 	app.applyProjectTheme()
 
-	fmt.Printf("📁 Loaded project: %s (%s) - Background: %s\n", projectName, app.projectList.GetColor(), backgroundImage)
+	fmt.Printf("📁 Loaded project: %s (%s) - Background: %s\n", projectName, app.projectList.GetColor(), meta.BackgroundImage)
 }
 
-// getProjectColor returns the color for a project
-func (app *TodoApp) getProjectColor(projectName string) string {
-	filename := filepath.Join("data/project", projectName+".txt")
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "blue"
-	}
-
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# Color: ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "# Color: "))
-		}
+// setProjectMeta saves project metadata and, if the project is the one
+// currently shown, applies its new theme
+func (app *TodoApp) setProjectMeta(meta ProjectMeta) error {
+	if err := app.store.UpdateProject(meta); err != nil {
+		return err
 	}
-	return "blue"
-}
 
-// getProjectBackgroundImage returns the background image for a project
-func (app *TodoApp) getProjectBackgroundImage(projectName string) string {
-	filename := filepath.Join("data/project", projectName+".txt")
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return ""
-	}
-
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# BackgroundImage: ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "# BackgroundImage: "))
-		}
+	if app.projectList != nil && app.currentProject == meta.Name {
+		app.projectList.SetColor(meta.Color)
+		app.projectList.SetTheme(meta.Color)
+		app.projectList.SetBackgroundImage(meta.BackgroundImage)
+		app.projectColor = meta.Color
+		app.applyProjectTheme()
 	}
-	return ""
+	return nil
 }
 
 // showCreateProjectDialog shows the create project dialog
@@ -670,7 +1019,7 @@ func (app *TodoApp) showCreateProjectDialog() {
 	nameEntry := widget.NewEntry()
 	nameEntry.SetPlaceHolder("Nhập tên project...")
 
-	colorSelect := widget.NewSelect([]string{"blue", "red", "green", "yellow", "orange", "purple", "brown", "black"}, nil)
+	colorSelect := widget.NewSelect(projectColors, nil)
 	colorSelect.SetSelected("blue")
 
 	// Background image selection
@@ -713,38 +1062,118 @@ func (app *TodoApp) showCreateProjectDialog() {
 
 // createProject creates a new project
 func (app *TodoApp) createProject(name, color string, backgroundImage ...string) {
-	projectDir := "data/project"
-	os.MkdirAll(projectDir, 0755)
-
-	filename := filepath.Join(projectDir, name+".txt")
-
-	// Build content with optional background image
-	content := fmt.Sprintf("# Project: %s\n# Color: %s\n# Created: %s\n",
-		name, color, time.Now().Format("2006-01-02 15:04:05"))
-
-	if len(backgroundImage) > 0 && backgroundImage[0] != "" {
-		content += fmt.Sprintf("# BackgroundImage: %s\n", backgroundImage[0])
+	meta := ProjectMeta{
+		Name:    name,
+		Color:   color,
+		Created: time.Now(),
+	}
+	if len(backgroundImage) > 0 {
+		meta.BackgroundImage = backgroundImage[0]
 	}
 
-	content += "\n"
-
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
+	if err := app.store.CreateProject(meta); err != nil {
 		dialog.ShowError(err, app.window)
 		return
 	}
 
+	var todos []Todo
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Tạo project: %s", name),
+		Undo: func() error {
+			removed, held, err := app.removeProject(name)
+			if err != nil {
+				return err
+			}
+			meta, todos = removed, held
+			return nil
+		},
+		Redo: func() error {
+			return app.restoreProject(meta, todos)
+		},
+	})
+
 	app.refreshProjectList()
 	app.projectSelect.SetSelected(name)
 
 	imageInfo := ""
-	if len(backgroundImage) > 0 && backgroundImage[0] != "" {
+	if meta.BackgroundImage != "" {
 		imageInfo = " với ảnh nền"
 	}
 
 	dialog.ShowInformation("Thành công", fmt.Sprintf("Đã tạo project: %s%s", name, imageInfo), app.window)
 }
 
+// removeProject deletes a project to undo its creation and returns what it
+// held by then, so that redo brings back the todos added since
+func (app *TodoApp) removeProject(name string) (ProjectMeta, []Todo, error) {
+	meta, err := app.store.LoadProject(name)
+	if err != nil {
+		return ProjectMeta{}, nil, err
+	}
+	todos := app.listFor(name).AllTodos()
+
+	if err := app.store.DeleteProject(name); err != nil {
+		return ProjectMeta{}, nil, err
+	}
+	if app.currentProject == name {
+		app.clearCurrentProject()
+	}
+	app.refreshProjectList()
+	return meta, todos, nil
+}
+
+// restoreProject creates a project again with the todos it held when it was
+// removed
+func (app *TodoApp) restoreProject(meta ProjectMeta, todos []Todo) error {
+	if err := app.store.CreateProject(meta); err != nil {
+		return err
+	}
+	if err := putTodos(app.listFor(meta.Name), todos); err != nil {
+		return err
+	}
+	app.refreshProjectList()
+	app.projectSelect.SetSelected(meta.Name)
+	return nil
+}
+
+// confirmDeleteProject asks before deleting the current project
+func (app *TodoApp) confirmDeleteProject() {
+	if app.currentProject == "" {
+		dialog.ShowInformation("Thông báo", "Chọn project trước khi xóa", app.window)
+		return
+	}
+
+	name := app.currentProject
+	dialog.ShowConfirm("Xác nhận xóa project",
+		fmt.Sprintf("Bạn có chắc chắn muốn xóa project '%s' cùng toàn bộ công việc?", name),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			if err := app.store.DeleteProject(name); err != nil {
+				dialog.ShowError(err, app.window)
+				return
+			}
+
+			app.clearCurrentProject()
+			app.refreshProjectList()
+			dialog.ShowInformation("Thành công", fmt.Sprintf("Đã xóa project: %s", name), app.window)
+		}, app.window)
+}
+
+// clearCurrentProject unloads the selected project
+func (app *TodoApp) clearCurrentProject() {
+	app.currentProject = ""
+	app.projectList = nil
+	app.projectSelect.ClearSelected()
+
+	if app.projectThemeInfo != nil {
+		app.projectThemeInfo.SetText("Chưa chọn project")
+	}
+	app.refreshViews(true, nil)
+}
+
 // showImageSelectionDialog shows dialog to select background image
 func (app *TodoApp) showImageSelectionDialog(callback func(string)) {
 	// Create file dialog for image selection
@@ -782,11 +1211,13 @@ func (app *TodoApp) showImageSelectionDialog(callback func(string)) {
 			return
 		}
 
-		// Copy image to themes directory
-		themesDir := "data/themes/images"
+		// Copy image to themes directory; the project keeps its path
+		// relative to the data root
+		themesDir := filepath.Join(app.dataDir, themeImagesDir)
 		os.MkdirAll(themesDir, 0755)
 
-		destPath := filepath.Join(themesDir, filepath.Base(imagePath))
+		imageFile := filepath.Join(themeImagesDir, filepath.Base(imagePath))
+		destPath := app.dataFile(imageFile)
 
 		// Copy file
 		sourceFile, err := os.Open(imagePath)
@@ -817,7 +1248,7 @@ func (app *TodoApp) showImageSelectionDialog(callback func(string)) {
 			return
 		}
 
-		callback(destPath)
+		callback(imageFile)
 	}, app.window)
 
 	fileDialog.Show()
@@ -833,7 +1264,7 @@ func (app *TodoApp) showProjectThemeDialog() {
 	currentImage := app.projectList.GetBackgroundImage()
 
 	// Color selection
-	colorSelect := widget.NewSelect([]string{"blue", "red", "green", "yellow", "orange", "purple", "brown", "black"}, nil)
+	colorSelect := widget.NewSelect(projectColors, nil)
 	colorSelect.SetSelected(currentColor)
 
 	// Current background image info
@@ -904,20 +1335,27 @@ func (app *TodoApp) showProjectThemeDialog() {
 				newColor = currentColor
 			}
 
-			app.projectList.SetColor(newColor)
-			app.projectList.SetTheme(newColor)
-			app.projectList.SetBackgroundImage(selectedImagePath)
-			app.projectColor = newColor
-
-			// Update project file
-			app.updateProjectFile(app.currentProject, newColor, selectedImagePath)
+			previous := app.projectList.Meta()
+			updated := previous
+			updated.Color = newColor
+			updated.BackgroundImage = selectedImagePath
 
-			// Apply new theme
-			app.applyProjectTheme()
+			if err := app.setProjectMeta(updated); err != nil {
+				dialog.ShowError(err, app.window)
+				return
+			}
 
-			dialog.ShowInformation("Thành công",
-				fmt.Sprintf("Đã cập nhật theme cho project %s", app.currentProject),
-				app.window)
+			app.undoStack.Push(Command{
+				Label: fmt.Sprintf("Đổi theme project %s", updated.Name),
+				Undo: func() error {
+					return app.setProjectMeta(previous)
+				},
+				Redo: func() error {
+					return app.setProjectMeta(updated)
+				},
+			})
+
+			app.showUndoSnackbar(fmt.Sprintf("Đã cập nhật theme cho project %s", updated.Name))
 		}
 	}, app.window)
 }
@@ -936,16 +1374,162 @@ func (app *TodoApp) showSettingsDialog() {
 
 	app.updateSwitchAppearance(themeSwitch)
 
+	restoreBtn := widget.NewButton("♻️ Khôi phục Todos từ bản sao lưu", func() {
+		app.showRestoreBackupDialog(app.todoList, "Todos")
+	})
+
+	// Storage backend selection
+	backendSelect := widget.NewSelect([]string{BackendText, BackendJSON, BackendEmbedded}, nil)
+	backendSelect.SetSelected(app.config.StorageBackend)
+	backendSelect.OnChanged = func(backend string) {
+		if backend == app.config.StorageBackend {
+			return
+		}
+		app.confirmSwitchBackend(backend, func() {
+			backendSelect.SetSelected(app.config.StorageBackend)
+		})
+	}
+
+	// Trash auto-purge age
+	purgeOptions := []string{"7 ngày", "30 ngày", "90 ngày", "Không bao giờ"}
+	purgeDays := []int{7, 30, 90, 0}
+	purgeSelect := widget.NewSelect(purgeOptions, nil)
+	for i, days := range purgeDays {
+		if days == app.config.TrashPurgeDays {
+			purgeSelect.SetSelectedIndex(i)
+		}
+	}
+	purgeSelect.OnChanged = func(string) {
+		index := purgeSelect.SelectedIndex()
+		if index < 0 || purgeDays[index] == app.config.TrashPurgeDays {
+			return
+		}
+		app.config.TrashPurgeDays = purgeDays[index]
+		if err := app.config.Save(); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		app.purgeTrash(app.todoList)
+		if app.projectList != nil {
+			app.purgeTrash(app.projectList.TodoList)
+		}
+		app.refreshAllLists()
+	}
+
+	// Lunar dates on the cards
+	lunarCheck := widget.NewCheck("Hiển thị ngày âm lịch trên công việc", func(checked bool) {
+		if checked == app.config.ShowLunarDates {
+			return
+		}
+		app.config.ShowLunarDates = checked
+		if err := app.config.Save(); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		app.refreshAllLists()
+	})
+	lunarCheck.SetChecked(app.config.ShowLunarDates)
+
 	content := container.NewVBox(
 		widget.NewLabel("Chọn giao diện sáng hoặc tối"),
 		widget.NewSeparator(),
 		themeLabel,
 		themeSwitch,
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, widget.NewLabel("Lưu trữ:"), nil, backendSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Tự dọn thùng rác sau:"), nil, purgeSelect),
+		lunarCheck,
+		restoreBtn,
 	)
 
 	dialog.ShowCustom("⚙️ Cài đặt", "Đóng", content, app.window)
 }
 
+// confirmSwitchBackend asks whether to copy the current data into the new
+// storage backend and then switches to it. onCancel restores the selector.
+func (app *TodoApp) confirmSwitchBackend(backend string, onCancel func()) {
+	newStore, err := NewStore(backend, app.dataDir)
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		onCancel()
+		return
+	}
+
+	content := widget.NewLabel(fmt.Sprintf(
+		"Chuyển lưu trữ từ '%s' sang '%s'.\nSao chép dữ liệu hiện tại sang backend mới?",
+		app.config.StorageBackend, backend))
+
+	confirm := dialog.NewCustomConfirm("💾 Đổi backend lưu trữ", "Sao chép & chuyển", "Chỉ chuyển", content, func(copyData bool) {
+		if copyData {
+			if err := CopyStore(app.store, newStore, app.listFor); err != nil {
+				dialog.ShowError(fmt.Errorf("không thể sao chép dữ liệu: %v", err), app.window)
+				onCancel()
+				return
+			}
+		}
+
+		app.config.StorageBackend = backend
+		if err := app.config.Save(); err != nil {
+			dialog.ShowError(err, app.window)
+		}
+
+		app.switchStore(newStore)
+		fmt.Printf("💾 Switched storage backend to %s\n", backend)
+	}, app.window)
+	confirm.Show()
+}
+
+// switchStore reloads the main list and projects from another store
+func (app *TodoApp) switchStore(store Store) {
+	app.store = store
+	app.undoStack.Clear()
+	app.todoList = NewTodoList(store, "")
+	app.purgeTrash(app.todoList)
+	app.clearCurrentProject()
+	app.refreshProjectList()
+	app.refreshAllLists()
+	app.startWatching()
+}
+
+// showRestoreBackupDialog lets the user restore a list from one of its backups
+func (app *TodoApp) showRestoreBackupDialog(list *TodoList, title string) {
+	backups := list.ListBackups()
+	if len(backups) == 0 {
+		dialog.ShowInformation("Thông báo", fmt.Sprintf("Chưa có bản sao lưu nào cho %s", title), app.window)
+		return
+	}
+
+	var options []string
+	for _, backup := range backups {
+		options = append(options, fmt.Sprintf("#%d • %s • %d công việc",
+			backup.Generation, backup.ModTime.Format("02/01 15:04:05"), backup.TodoCount))
+	}
+
+	backupSelect := widget.NewSelect(options, nil)
+	backupSelect.SetSelectedIndex(0)
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Chọn bản sao lưu để khôi phục cho %s:", title)),
+		backupSelect,
+		widget.NewLabel("Dữ liệu hiện tại sẽ được lưu thành bản sao lưu mới."),
+	)
+
+	dialog.ShowCustomConfirm("♻️ Khôi phục", "Khôi phục", "Hủy", content, func(response bool) {
+		index := backupSelect.SelectedIndex()
+		if !response || index < 0 {
+			return
+		}
+
+		if err := list.RestoreBackup(backups[index].Path); err != nil {
+			app.showListError(err)
+			return
+		}
+
+		app.refreshAllLists()
+		dialog.ShowInformation("Thành công", fmt.Sprintf("Đã khôi phục %s từ bản sao lưu #%d", title, backups[index].Generation), app.window)
+	}, app.window)
+}
+
 // applyTheme applies the selected theme
 func (app *TodoApp) applyTheme() {
 	var customTheme fyne.Theme
@@ -1016,66 +1600,6 @@ func (t *customLightTheme) Size(name fyne.ThemeSizeName) float32 {
 	return theme.DefaultTheme().Size(name)
 }
 
-// updateProjectFile updates the project file with new color and background image information
-func (app *TodoApp) updateProjectFile(projectName, color, backgroundImage string) {
-	filename := filepath.Join("data/project", projectName+".txt")
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return
-	}
-
-	lines := strings.Split(string(content), "\n")
-	colorUpdated := false
-	backgroundUpdated := false
-
-	for i, line := range lines {
-		if strings.HasPrefix(line, "# Color: ") {
-			lines[i] = fmt.Sprintf("# Color: %s", color)
-			colorUpdated = true
-		} else if strings.HasPrefix(line, "# BackgroundImage: ") {
-			if backgroundImage != "" {
-				lines[i] = fmt.Sprintf("# BackgroundImage: %s", backgroundImage)
-			} else {
-				// Remove background image line if empty
-				lines = append(lines[:i], lines[i+1:]...)
-			}
-			backgroundUpdated = true
-		}
-	}
-
-	// Add missing fields if not found
-	if !colorUpdated {
-		// Insert after project name line
-		for i, line := range lines {
-			if strings.HasPrefix(line, "# Project: ") {
-				newLines := make([]string, 0, len(lines)+1)
-				newLines = append(newLines, lines[:i+1]...)
-				newLines = append(newLines, fmt.Sprintf("# Color: %s", color))
-				newLines = append(newLines, lines[i+1:]...)
-				lines = newLines
-				break
-			}
-		}
-	}
-
-	if !backgroundUpdated && backgroundImage != "" {
-		// Insert after color line
-		for i, line := range lines {
-			if strings.HasPrefix(line, "# Color: ") {
-				newLines := make([]string, 0, len(lines)+1)
-				newLines = append(newLines, lines[:i+1]...)
-				newLines = append(newLines, fmt.Sprintf("# BackgroundImage: %s", backgroundImage))
-				newLines = append(newLines, lines[i+1:]...)
-				lines = newLines
-				break
-			}
-		}
-	}
-
-	updatedContent := strings.Join(lines, "\n")
-	os.WriteFile(filename, []byte(updatedContent), 0644)
-}
-
 // resetProjectTabContent resets project tab to clean state without theme
 func (app *TodoApp) resetProjectTabContent(projectTab *container.TabItem) {
 	// Store original content structure without theme layers
@@ -1111,10 +1635,28 @@ func (app *TodoApp) getBaseProjectTabContent() *fyne.Container {
 	})
 	projectSettingsBtn.Importance = widget.MediumImportance
 
-	projectButtons := container.NewHBox(projectSettingsBtn, addProjectBtn)
+	// Project restore button
+	projectRestoreBtn := widget.NewButton("♻️ Khôi phục", func() {
+		if app.projectList == nil || app.currentProject == "" {
+			dialog.ShowInformation("Thông báo", "Chọn project trước khi khôi phục", app.window)
+			return
+		}
+		app.showRestoreBackupDialog(app.projectList.TodoList, app.currentProject)
+	})
+
+	// Delete project button
+	deleteProjectBtn := widget.NewButton("🗑️", func() {
+		app.confirmDeleteProject()
+	})
+	deleteProjectBtn.Importance = widget.DangerImportance
+
+	projectButtons := container.NewHBox(projectSettingsBtn, projectRestoreBtn, app.createTransferButton(true), deleteProjectBtn, addProjectBtn)
 	projectSelector := container.NewBorder(nil, nil, nil, projectButtons, app.projectSelect)
 
 	// Project todo input
+	if app.projectPrioritySelect == nil {
+		app.projectPrioritySelect = newPrioritySelect()
+	}
 	if app.projectTodoEntry == nil {
 		app.projectTodoEntry = widget.NewEntry()
 		app.projectTodoEntry.SetPlaceHolder("Nhập công việc cho project...")
@@ -1125,7 +1667,7 @@ func (app *TodoApp) getBaseProjectTabContent() *fyne.Container {
 				dialog.ShowInformation("Thông báo", "Chọn project trước khi thêm todo", app.window)
 				return
 			}
-			app.addTodo(text, true)
+			app.addTodo(text, app.projectPriority(), true)
 			app.projectTodoEntry.SetText("")
 		}
 	}
@@ -1135,19 +1677,15 @@ func (app *TodoApp) getBaseProjectTabContent() *fyne.Container {
 			dialog.ShowInformation("Thông báo", "Chọn project trước khi thêm todo", app.window)
 			return
 		}
-		app.addTodo(app.projectTodoEntry.Text, true)
+		app.addTodo(app.projectTodoEntry.Text, app.projectPriority(), true)
 		app.projectTodoEntry.SetText("")
 	})
 	addProjectTodoBtn.Importance = widget.HighImportance
 
-	projectTodoInputContainer := container.NewBorder(nil, nil, nil, addProjectTodoBtn, app.projectTodoEntry)
+	projectTodoInputContainer := container.NewBorder(nil, nil, nil, container.NewHBox(app.projectPrioritySelect, addProjectTodoBtn), app.projectTodoEntry)
 
 	// Project sub-tabs
-	projectSubTabs := container.NewAppTabs(
-		container.NewTabItem("Tất cả", container.NewScroll(app.projectAllList)),
-		container.NewTabItem("Chưa hoàn thành", container.NewScroll(app.projectActiveList)),
-		container.NewTabItem("Đã hoàn thành", container.NewScroll(app.projectCompletedList)),
-	)
+	projectSubTabs := app.createSubTabs(true)
 
 	// Project theme info
 	if app.projectThemeInfo == nil {
@@ -1165,6 +1703,7 @@ func (app *TodoApp) getBaseProjectTabContent() *fyne.Container {
 			widget.NewSeparator(),
 			projectTodoInputContainer,
 			widget.NewSeparator(),
+			app.createTagFilterBar(true),
 		),
 		nil, nil, nil,
 		projectSubTabs,
@@ -1185,7 +1724,7 @@ func (app *TodoApp) applyProjectTheme() {
 	projectTab := app.tabs.Items[projectTabIndex]
 	projectColor := app.projectList.GetColor()
 	projectName := app.projectList.GetName()
-	backgroundImage := app.projectList.GetBackgroundImage()
+	backgroundImage := app.dataFile(app.projectList.GetBackgroundImage())
 
 	// Update tab title with color indicator
 	colorEmoji := app.getColorEmoji(projectColor)
@@ -1235,7 +1774,7 @@ func (app *TodoApp) createProjectThemedContainer(originalContent fyne.CanvasObje
 	}
 
 	// Only add background image if it exists and is valid
-	backgroundImagePath := app.projectList.GetBackgroundImage()
+	backgroundImagePath := app.dataFile(app.projectList.GetBackgroundImage())
 	if backgroundImagePath != "" {
 		if _, err := os.Stat(backgroundImagePath); err == nil {
 			imageResource, err := fyne.LoadResourceFromPath(backgroundImagePath)
@@ -1298,35 +1837,6 @@ func (app *TodoApp) getColorEmoji(color string) string {
 	}
 }
 
-// migrateOldProjectFile adds header metadata to old project files that don't have it
-func (app *TodoApp) migrateOldProjectFile(projectName string) {
-	filename := filepath.Join("data/project", projectName+".txt")
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return
-	}
-
-	// Check if file already has header
-	lines := strings.Split(string(content), "\n")
-	hasHeader := false
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# Project:") {
-			hasHeader = true
-			break
-		}
-	}
-
-	// If file doesn't have header, add it
-	if !hasHeader {
-		header := fmt.Sprintf("# Project: %s\n# Color: blue\n# Created: %s\n\n",
-			projectName, time.Now().Format("2006-01-02 15:04:05"))
-		newContent := header + string(content)
-
-		os.WriteFile(filename, []byte(newContent), 0644)
-		fmt.Printf("🔄 Migrated old project file: %s\n", projectName)
-	}
-}
-
 // createThemedProjectTabContent recreates the project tab content with theme applied
 func (app *TodoApp) createThemedProjectTabContent(themeMessage string) *fyne.Container {
 	// Don't recreate the entire tab content, just return the existing one with theme info
@@ -1347,3 +1857,152 @@ func (app *TodoApp) createThemedProjectTabContent(themeMessage string) *fyne.Con
 
 	return existingContent
 }
+
+// createTrashTabContent creates the "Thùng rác" sub-tab with its empty button
+func (app *TodoApp) createTrashTabContent(list fyne.CanvasObject, isProject bool) fyne.CanvasObject {
+	emptyBtn := widget.NewButton("🧹 Dọn sạch thùng rác", func() {
+		app.confirmEmptyTrash(isProject)
+	})
+	emptyBtn.Importance = widget.DangerImportance
+
+	return container.NewBorder(
+		container.NewHBox(emptyBtn),
+		nil, nil, nil,
+		container.NewScroll(list),
+	)
+}
+
+// updateTrashItem renders a todo of the trash with restore and purge buttons
+func (app *TodoApp) updateTrashItem(todo Todo, card *widget.Card, isProject bool) {
+	// Deletion date label
+	dateLabel := widget.NewLabel("🗑️ " + todo.DeletedAt.Format("02/01 15:04"))
+	dateLabel.TextStyle = fyne.TextStyle{Italic: true}
+
+	// Content label
+	contentLabel := widget.NewLabel(todo.Description)
+	contentLabel.Wrapping = fyne.TextWrapWord
+
+	restoreBtn := widget.NewButton("♻️", func() {
+		app.restoreTodo(todo.ID, isProject)
+	})
+	purgeBtn := widget.NewButton("❌", func() {
+		app.confirmPurgeTodo(todo, isProject)
+	})
+
+	horizontalLayout := container.NewBorder(
+		nil, nil,
+		dateLabel,
+		container.NewHBox(restoreBtn, purgeBtn),
+		contentLabel,
+	)
+
+	card.SetContent(container.NewPadded(horizontalLayout))
+}
+
+// showTrashItemDialog shows the actions for a todo in the trash
+func (app *TodoApp) showTrashItemDialog(todo Todo, isProject bool) {
+	var actions dialog.Dialog
+
+	restoreBtn := widget.NewButton("♻️ Khôi phục", func() {
+		actions.Hide()
+		app.restoreTodo(todo.ID, isProject)
+	})
+	restoreBtn.Importance = widget.SuccessImportance
+
+	purgeBtn := widget.NewButton("❌ Xóa vĩnh viễn", func() {
+		actions.Hide()
+		app.confirmPurgeTodo(todo, isProject)
+	})
+	purgeBtn.Importance = widget.DangerImportance
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Công việc: %s", todo.Description)),
+		widget.NewLabel(fmt.Sprintf("Đã xóa lúc: %s", todo.DeletedAt.Format("02/01/2006 15:04"))),
+		widget.NewSeparator(),
+		restoreBtn,
+		purgeBtn,
+	)
+
+	actions = dialog.NewCustom("Thùng rác", "Hủy", content, app.window)
+	actions.Show()
+}
+
+// restoreTodo moves a todo out of the trash
+func (app *TodoApp) restoreTodo(todoID int, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	if err := list.RestoreTodo(todoID); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	restored, _ := list.GetTodo(todoID)
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Khôi phục: %s", restored.Description),
+		Undo: func() error {
+			return app.listFor(project).DeleteTodo(todoID)
+		},
+		Redo: func() error {
+			return app.listFor(project).RestoreTodo(todoID)
+		},
+	})
+
+	app.refreshAllLists()
+	app.showUndoSnackbar(fmt.Sprintf("Đã khôi phục: %s", restored.Description))
+}
+
+// confirmPurgeTodo asks before permanently deleting a todo from the trash
+func (app *TodoApp) confirmPurgeTodo(todo Todo, isProject bool) {
+	dialog.ShowConfirm("Xóa vĩnh viễn",
+		fmt.Sprintf("Xóa vĩnh viễn '%s'?\nThao tác này không thể hoàn tác.", todo.Description),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := app.listFor(app.projectKey(isProject)).PurgeTodo(todo.ID); err != nil {
+				app.showListError(err)
+				return
+			}
+			app.refreshAllLists()
+		}, app.window)
+}
+
+// confirmEmptyTrash asks before permanently deleting everything in the trash
+func (app *TodoApp) confirmEmptyTrash(isProject bool) {
+	if isProject && app.projectList == nil {
+		return
+	}
+
+	list := app.listFor(app.projectKey(isProject))
+	count := len(list.GetTrash())
+	if count == 0 {
+		dialog.ShowInformation("Thùng rác", "Thùng rác đang trống", app.window)
+		return
+	}
+
+	dialog.ShowConfirm("Dọn sạch thùng rác",
+		fmt.Sprintf("Xóa vĩnh viễn %d công việc trong thùng rác?\nThao tác này không thể hoàn tác.", count),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := list.EmptyTrash(); err != nil {
+				app.showListError(err)
+				return
+			}
+			app.refreshAllLists()
+		}, app.window)
+}
+
+// purgeTrash removes trashed todos older than the configured age
+func (app *TodoApp) purgeTrash(list *TodoList) {
+	count, err := list.PurgeTrash(app.config.TrashPurgeAge())
+	if err != nil {
+		fmt.Printf("❌ Error purging trash: %v\n", err)
+		return
+	}
+	if count > 0 {
+		fmt.Printf("🧹 Purged %d todos from trash\n", count)
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Markdown checklist of a project:
+//
+//	# Dự án X
+//
+//	Màu: blue
+//	Ngày tạo: 2025-10-01
+//
+//	## Công việc
+//
+//	- [ ] Chuẩn bị kế hoạch #team ⏫ 📅 2025-10-05
+//	  - [x] Viết dàn ý ✅ 2025-10-02
+//
+// Nested items are subtasks. Priorities and dates use the emoji of the
+// Obsidian Tasks plugin, tags are #words.
+const (
+	markdownDate = "2006-01-02"
+
+	markdownColor    = "Màu:"
+	markdownCreated  = "Ngày tạo:"
+	markdownTodos    = "## Công việc"
+	markdownDue      = "📅"
+	markdownStart    = "🛫"
+	markdownDone     = "✅"
+	markdownAdded    = "➕"
+	markdownTabWidth = 4
+)
+
+// markdownPriorities are the emoji of the low, medium, high and urgent
+// priorities
+var markdownPriorities = map[Priority]string{
+	PriorityLow:    "🔽",
+	PriorityMedium: "🔼",
+	PriorityHigh:   "⏫",
+	PriorityUrgent: "🔺",
+}
+
+// markdownItem matches a checklist item: indentation, checkbox and text
+var markdownItem = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\][ \t]+(.*)$`)
+
+// encodeMarkdownItem returns the text of a checklist item after the checkbox
+func encodeMarkdownItem(todo Todo) string {
+	words := strings.Fields(todo.Description)
+	for _, tag := range todo.Tags {
+		words = append(words, "#"+tag)
+	}
+	if emoji, ok := markdownPriorities[todo.Priority]; ok {
+		words = append(words, emoji)
+	}
+	date := func(emoji string, t time.Time) {
+		if !t.IsZero() {
+			words = append(words, emoji, t.Local().Format(markdownDate))
+		}
+	}
+	date(markdownStart, todo.StartAt)
+	date(markdownDue, todo.DueAt)
+	date(markdownAdded, todo.CreatedAt)
+	if todo.Completed {
+		date(markdownDone, todo.CompletedAt)
+	}
+	return strings.Join(words, " ")
+}
+
+// WriteMarkdown writes a project and its todos, outside the trash, as a
+// Markdown checklist. Subtasks follow their parent, indented, in manual
+// order.
+func WriteMarkdown(w io.Writer, meta ProjectMeta, todos []Todo) error {
+	todos = slices.DeleteFunc(slices.Clone(todos), Todo.InTrash)
+	sortTodos(todos, SortManual)
+	ordered, depths := todoTree(todos, nil)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", meta.Name)
+	fmt.Fprintf(bw, "%s %s  \n", markdownColor, meta.Color)
+	if !meta.Created.IsZero() {
+		fmt.Fprintf(bw, "%s %s\n", markdownCreated, meta.Created.Local().Format(markdownDate))
+	}
+	if meta.Description != "" {
+		fmt.Fprintf(bw, "\n%s\n", meta.Description)
+	}
+	fmt.Fprintf(bw, "\n%s\n\n", markdownTodos)
+	for i, todo := range ordered {
+		check := " "
+		if todo.Completed {
+			check = "x"
+		}
+		fmt.Fprintf(bw, "%s- [%s] %s\n", strings.Repeat("  ", depths[i]), check, encodeMarkdownItem(todo))
+	}
+	return bw.Flush()
+}
+
+// parseMarkdownItem parses the text of a checklist item after the checkbox
+func parseMarkdownItem(text string, completed bool) Todo {
+	todo := Todo{Completed: completed}
+	words := strings.Fields(text)
+	var description []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if priority, ok := markdownPriority(word); ok {
+			todo.Priority = priority
+			continue
+		}
+		// #12 is rather a reference to an issue than a tag
+		if len(word) > 1 && word[0] == '#' && strings.Trim(word[1:], "0123456789") != "" {
+			if tags := addTag(todo.Tags, word[1:]); len(tags) > len(todo.Tags) {
+				todo.Tags = tags
+				continue
+			}
+		}
+		if i+1 < len(words) {
+			if date, err := time.ParseInLocation(markdownDate, words[i+1], time.Local); err == nil {
+				switch word {
+				case markdownDue:
+					todo.DueAt = date
+				case markdownStart:
+					todo.StartAt = date
+				case markdownAdded:
+					todo.CreatedAt = date
+				case markdownDone:
+					todo.CompletedAt = date
+				default:
+					description = append(description, word)
+					continue
+				}
+				i++
+				continue
+			}
+		}
+		description = append(description, word)
+	}
+	todo.Description = strings.Join(description, " ")
+	if !todo.Completed {
+		todo.CompletedAt = time.Time{}
+	}
+	return todo
+}
+
+// markdownPriority returns the priority of a priority emoji
+func markdownPriority(word string) (Priority, bool) {
+	for priority, emoji := range markdownPriorities {
+		if word == emoji {
+			return priority, true
+		}
+	}
+	return PriorityNone, false
+}
+
+// markdownIndent returns the width of the indentation of a line
+func markdownIndent(indent string) int {
+	width := 0
+	for _, c := range indent {
+		if c == '\t' {
+			width += markdownTabWidth - width%markdownTabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// ReadMarkdown reads a project from a Markdown checklist. The first heading
+// is the project name; "Màu:" and "Ngày tạo:" lines and the text before
+// the checklist are its metadata. Every checklist item in the file is a
+// todo, a nested item a subtask of the item above it. The todos have
+// placeholder IDs below 1, see ImportTodos.
+func ReadMarkdown(r io.Reader) (ProjectMeta, []Todo, error) {
+	var meta ProjectMeta
+	var todos []Todo
+	var description []string
+
+	// The items enclosing the current line, outermost first
+	type openItem struct {
+		indent, id int
+	}
+	var open []openItem
+	inHeader := true
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := markdownItem.FindStringSubmatch(line); m != nil {
+			inHeader = false
+			todo := parseMarkdownItem(m[3], m[2] != " ")
+			if todo.Description == "" {
+				continue
+			}
+
+			indent := markdownIndent(m[1])
+			for len(open) > 0 && open[len(open)-1].indent >= indent {
+				open = open[:len(open)-1]
+			}
+			todo.ID = -(len(todos) + 1)
+			if len(open) > 0 {
+				todo.ParentID = open[len(open)-1].id
+			}
+			todo.Position = len(todos) + 1
+			todos = append(todos, todo)
+			open = append(open, openItem{indent: indent, id: todo.ID})
+			continue
+		}
+
+		text := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(text, "# ") && meta.Name == "":
+			meta.Name = strings.TrimSpace(text[2:])
+		case strings.HasPrefix(text, "#"):
+			// Sections of the checklist end the metadata
+			inHeader = false
+		case !inHeader || text == "":
+		case strings.HasPrefix(text, markdownColor):
+			color := strings.TrimSpace(strings.TrimPrefix(text, markdownColor))
+			if slices.Contains(projectColors, color) {
+				meta.Color = color
+			}
+		case strings.HasPrefix(text, markdownCreated):
+			created := strings.TrimSpace(strings.TrimPrefix(text, markdownCreated))
+			if date, err := time.ParseInLocation(markdownDate, created, time.Local); err == nil {
+				meta.Created = date
+			}
+		default:
+			description = append(description, text)
+		}
+	}
+	meta.Description = strings.Join(description, " ")
+	return meta, todos, scanner.Err()
+}
+
+// exportMarkdown saves the current project as a Markdown checklist
+func (app *TodoApp) exportMarkdown() {
+	meta := app.projectList.Meta()
+	todos := app.projectList.GetTodos()
+	app.exportFile(app.transferFileName(true, ".md"), func(w io.Writer) error {
+		return WriteMarkdown(w, meta, todos)
+	})
+}
+
+// importMarkdown reads a Markdown checklist and asks which project it goes to
+func (app *TodoApp) importMarkdown() {
+	app.importFile(func(r io.Reader) {
+		meta, todos, err := ReadMarkdown(r)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("không thể đọc file: %v", err), app.window)
+			return
+		}
+		if len(todos) == 0 {
+			dialog.ShowError(fmt.Errorf("không có mục checklist nào (- [ ] hoặc - [x]) trong file"), app.window)
+			return
+		}
+		app.showMarkdownImportDialog(meta, todos)
+	})
+}
+
+// showMarkdownImportDialog lets the user import a checklist into a new
+// project, named after its heading, or into an existing one
+func (app *TodoApp) showMarkdownImportDialog(meta ProjectMeta, todos []Todo) {
+	const (
+		newOption      = "Project mới"
+		existingOption = "Project có sẵn"
+	)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(meta.Name)
+	nameEntry.SetPlaceHolder("Nhập tên project...")
+
+	var projects []string
+	infos, err := app.store.ListProjects()
+	if err != nil {
+		fmt.Printf("❌ Error listing projects: %v\n", err)
+	}
+	for _, info := range infos {
+		projects = append(projects, info.Name)
+	}
+	projectSelect := widget.NewSelect(projects, nil)
+	switch {
+	case slices.Contains(projects, meta.Name):
+		projectSelect.SetSelected(meta.Name)
+	case app.currentProject != "":
+		projectSelect.SetSelected(app.currentProject)
+	}
+
+	target := widget.NewRadioGroup([]string{newOption, existingOption}, func(selected string) {
+		if selected == newOption {
+			nameEntry.Enable()
+			projectSelect.Disable()
+		} else {
+			nameEntry.Disable()
+			projectSelect.Enable()
+		}
+	})
+	target.Required = true
+	if len(projects) == 0 {
+		target.Disable()
+	}
+	if meta.Name != "" && slices.Contains(projects, meta.Name) {
+		target.SetSelected(existingOption)
+	} else {
+		target.SetSelected(newOption)
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Nhập %d công việc vào:", len(todos))),
+		target,
+		widget.NewFormItem("Tên:", nameEntry).Widget,
+		widget.NewFormItem("Project:", projectSelect).Widget,
+	)
+	d := dialog.NewCustomConfirm("📥 Nhập Markdown", "Nhập", "Hủy", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if target.Selected == existingOption {
+			if projectSelect.Selected == "" {
+				return
+			}
+			app.importTodos(projectSelect.Selected, todos)
+			app.projectSelect.SetSelected(projectSelect.Selected)
+			return
+		}
+
+		meta.Name = strings.TrimSpace(nameEntry.Text)
+		if meta.Name == "" {
+			dialog.ShowError(fmt.Errorf("tên project không được để trống"), app.window)
+			return
+		}
+		app.importMarkdownProject(meta, todos)
+	}, app.window)
+	d.Resize(fyne.NewSize(400, 0))
+	d.Show()
+}
+
+// importMarkdownProject creates a project with the todos of a checklist,
+// with undo
+func (app *TodoApp) importMarkdownProject(meta ProjectMeta, todos []Todo) {
+	if meta.Color == "" {
+		meta.Color = projectColors[0]
+	}
+	if meta.Created.IsZero() {
+		meta.Created = time.Now()
+	}
+	if err := app.store.CreateProject(meta); err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+
+	added, err := app.listFor(meta.Name).ImportTodos(todos)
+	name := meta.Name
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Nhập project: %s", name),
+		Undo: func() error {
+			removed, held, err := app.removeProject(name)
+			if err != nil {
+				return err
+			}
+			meta, todos = removed, held
+			return nil
+		},
+		Redo: func() error {
+			return app.restoreProject(meta, todos)
+		},
+	})
+
+	app.refreshProjectList()
+	app.projectSelect.SetSelected(meta.Name)
+	if err != nil {
+		app.showListError(err)
+		return
+	}
+	app.snackbar.Show(fmt.Sprintf("Đã nhập project %s (%d công việc)", meta.Name, len(added)), "Hoàn tác", app.undo)
+}
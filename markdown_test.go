@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 10, d, 0, 0, 0, 0, time.Local)
+	}
+	meta := ProjectMeta{Name: "Dự án X", Color: "green", Created: day(1), Description: "Kế hoạch quý 4"}
+	todos := []Todo{
+		{ID: 1, Description: "Chuẩn bị kế hoạch", Tags: []string{"team"}, Priority: PriorityHigh, DueAt: day(5), CreatedAt: day(1)},
+		{ID: 2, Description: "Viết dàn ý", ParentID: 1, Completed: true, CompletedAt: day(2), CreatedAt: day(1)},
+		{ID: 3, Description: "Xin ý kiến", ParentID: 2, StartAt: day(3), Priority: PriorityUrgent},
+		{ID: 4, Description: "Đặt phòng họp", Priority: PriorityLow},
+		{ID: 5, Description: "Trong thùng rác", DeletedAt: day(4)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, meta, todos); err != nil {
+		t.Fatal(err)
+	}
+	gotMeta, got, err := ReadMarkdown(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMeta.Name != meta.Name || gotMeta.Color != meta.Color || !gotMeta.Created.Equal(meta.Created) || gotMeta.Description != meta.Description {
+		t.Errorf("meta = %+v, want %+v", gotMeta, meta)
+	}
+
+	// Trashed todos are not exported; IDs become placeholders
+	if len(got) != 4 {
+		t.Fatalf("read %d todos: %+v", len(got), got)
+	}
+	byDescription := map[string]Todo{}
+	for _, todo := range got {
+		byDescription[todo.Description] = todo
+	}
+	for _, want := range todos[:4] {
+		todo, ok := byDescription[want.Description]
+		if !ok {
+			t.Errorf("missing %q", want.Description)
+			continue
+		}
+		if todo.Completed != want.Completed || todo.Priority != want.Priority || !slices.Equal(todo.Tags, want.Tags) ||
+			!todo.DueAt.Equal(want.DueAt) || !todo.StartAt.Equal(want.StartAt) ||
+			!todo.CreatedAt.Equal(want.CreatedAt) || !todo.CompletedAt.Equal(want.CompletedAt) {
+			t.Errorf("read %+v, want %+v", todo, want)
+		}
+	}
+	parentOf := func(description string) string {
+		for _, todo := range got {
+			if todo.ID == byDescription[description].ParentID {
+				return todo.Description
+			}
+		}
+		return ""
+	}
+	if parentOf("Viết dàn ý") != "Chuẩn bị kế hoạch" || parentOf("Xin ý kiến") != "Viết dàn ý" || parentOf("Đặt phòng họp") != "" {
+		t.Errorf("subtasks lost their parents: %+v", got)
+	}
+}
+
+func TestReadMarkdown(t *testing.T) {
+	text := strings.Join([]string{
+		"# Việc nhà",
+		"",
+		"Màu: tím",
+		"Ghi chú đầu",
+		"",
+		"## Tuần này",
+		"",
+		"1. [ ] Dọn bếp #12 🔼",
+		"\t2) [X] Rửa bát 🗓️ 2025-10-05",
+		"* [ ] Đi chợ",
+		"    + [ ] Mua rau",
+		"- [ ]   ",
+		"- [] không phải mục",
+		"Ngày tạo: 2025-10-01",
+	}, "\n")
+
+	meta, todos, err := ReadMarkdown(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "Việc nhà" || meta.Color != "" || meta.Description != "Ghi chú đầu" || !meta.Created.IsZero() {
+		t.Errorf("meta = %+v", meta)
+	}
+
+	want := []Todo{
+		{ID: -1, Description: "Dọn bếp #12", Priority: PriorityMedium, Position: 1},
+		{ID: -2, Description: "Rửa bát 🗓️ 2025-10-05", Completed: true, ParentID: -1, Position: 2},
+		{ID: -3, Description: "Đi chợ", Position: 3},
+		{ID: -4, Description: "Mua rau", ParentID: -3, Position: 4},
+	}
+	if !sameTodos(todos, want) {
+		t.Errorf("todos = %+v, want %+v", todos, want)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Choices of the merge conflict dialog
+const (
+	keepOursOption   = "Giữ bản của tôi"
+	keepTheirsOption = "Dùng bản trên đĩa"
+)
+
+// MergeConflict is a todo changed differently in this app and on disk since
+// the list was last read or written. A nil version was deleted.
+type MergeConflict struct {
+	ID     int
+	Base   *Todo // As last read or written
+	Ours   *Todo // As changed in this app
+	Theirs *Todo // As changed on disk
+}
+
+// MergeConflictError is returned by the writes of a list when the changes
+// on disk conflict with the changes in this app. Nothing is written; the
+// changes stay in memory until ResolveConflicts is called.
+type MergeConflictError struct {
+	Project   string // "" for the main list
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("%d công việc đã bị thay đổi khác nhau trong ứng dụng và trên đĩa", len(e.Conflicts))
+}
+
+// sameTodo reports whether two versions of a todo are equal as stored in a
+// list file, so that the time precision dropped by the file is ignored
+func sameTodo(a, b *Todo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return encodeTodo(*a) == encodeTodo(*b)
+}
+
+// sameTodos reports whether two lists hold the same todos
+func sameTodos(a, b []Todo) bool {
+	return slices.EqualFunc(a, b, func(x, y Todo) bool {
+		return sameTodo(&x, &y)
+	})
+}
+
+// todosByID indexes copies of todos by ID
+func todosByID(todos []Todo) map[int]*Todo {
+	byID := make(map[int]*Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = &todo
+	}
+	return byID
+}
+
+// mergeTodos merges the changes made to base in this app (ours) and on disk
+// (theirs), todo by todo. A todo changed on one side only takes that
+// change; a todo changed on both sides in different ways is a conflict and
+// keeps the version on disk. Todos added on both sides with the same ID are
+// both kept, ours getting a new ID; renamed maps their old IDs to the new.
+func mergeTodos(base, ours, theirs []Todo) (merged []Todo, conflicts []MergeConflict, renamed map[int]int) {
+	baseByID, oursByID, theirsByID := todosByID(base), todosByID(ours), todosByID(theirs)
+
+	maxID := 0
+	var ids []int
+	for _, byID := range []map[int]*Todo{baseByID, oursByID, theirsByID} {
+		for id := range byID {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+			maxID = max(maxID, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var moved []Todo
+	newIDs := make(map[int]int)
+	keep := func(todo *Todo) {
+		if todo != nil {
+			merged = append(merged, *todo)
+		}
+	}
+	for _, id := range ids {
+		b, o, t := baseByID[id], oursByID[id], theirsByID[id]
+		switch {
+		case sameTodo(o, t), sameTodo(b, t):
+			keep(o)
+		case sameTodo(b, o):
+			keep(t)
+		case b == nil:
+			keep(t)
+			maxID++
+			newIDs[id] = maxID
+			todo := *o
+			todo.ID = maxID
+			moved = append(moved, todo)
+		default:
+			keep(t)
+			conflicts = append(conflicts, MergeConflict{ID: id, Base: b, Ours: o, Theirs: t})
+		}
+	}
+
+	// Subtasks added in this app follow their renamed parent
+	for i := range moved {
+		if newID, ok := newIDs[moved[i].ParentID]; ok {
+			moved[i].ParentID = newID
+		}
+	}
+	for i := range merged {
+		if baseByID[merged[i].ID] != nil || theirsByID[merged[i].ID] != nil {
+			continue
+		}
+		if newID, ok := newIDs[merged[i].ParentID]; ok {
+			merged[i].ParentID = newID
+		}
+	}
+	return append(merged, moved...), conflicts, newIDs
+}
+
+// resolveConflict replaces the version of a conflicting todo in todos by
+// the version of this app
+func resolveConflict(todos []Todo, conflict MergeConflict) []Todo {
+	todos = slices.DeleteFunc(todos, func(todo Todo) bool {
+		return todo.ID == conflict.ID
+	})
+	if conflict.Ours != nil {
+		todos = insertTodoByID(todos, *conflict.Ours)
+	}
+	return todos
+}
+
+// mergeFiles merges the changes in the data files into the list in memory
+// and reports whether there were any. gen is the generation of the files.
+// Conflicts are resolved by keepOurs, or returned as a *MergeConflictError
+// if it is nil. The caller holds the file lock.
+func (tl *TodoList) mergeFiles(gen int64, keepOurs func(MergeConflict) bool) (bool, error) {
+	snapshot, theirs, err := tl.readFiles()
+	if err != nil {
+		return false, err
+	}
+
+	base := tl.base
+	if tl.editedOutside(gen, snapshot) {
+		// The program only saw the snapshot: replaying the journal on top
+		// of its edits would undo them. Its changes are those made to the
+		// snapshot, and the journal holds changes of this app.
+		base, theirs = tl.snapshot, snapshot
+	} else if sameTodos(theirs, tl.base) {
+		// e.g. the file was only touched, or another list of the same
+		// database file changed
+		return false, nil
+	}
+
+	todos, conflicts, renamed := mergeTodos(base, tl.todos, theirs)
+	if len(conflicts) > 0 && keepOurs == nil {
+		tl.unsaved = true
+		return false, &MergeConflictError{Project: tl.project, Conflicts: conflicts}
+	}
+	for _, conflict := range conflicts {
+		if keepOurs(conflict) {
+			todos = resolveConflict(todos, conflict)
+		}
+	}
+
+	tl.setTodos(todos)
+	tl.renamed = renamed
+	fmt.Printf("🔀 Merged changes on disk into list %q (%d conflicts)\n", tl.project, len(conflicts))
+	return true, nil
+}
+
+// editedOutside reports whether a program other than this app edited the
+// snapshot read from the data files of generation gen: instances of the app
+// bump the generation on every write, see writeMerged
+func (tl *TodoList) editedOutside(gen int64, snapshot []Todo) bool {
+	return gen == tl.generation && !sameTodos(snapshot, tl.snapshot)
+}
+
+// afterWrite returns a todo of this app as it is after the last write,
+// whose merge may have given it a new ID, see mergeTodos
+func (tl *TodoList) afterWrite(todo Todo) Todo {
+	id := todo.ID
+	if newID, ok := tl.renamed[id]; ok {
+		id = newID
+	}
+	if i := slices.IndexFunc(tl.todos, func(t Todo) bool { return t.ID == id }); i >= 0 {
+		return tl.todos[i]
+	}
+	return todo
+}
+
+// ResolveConflicts merges the changes on disk again and writes the result,
+// keeping the version of this app for the conflicting todos whose ID is in
+// keepOurs and the version on disk for the others
+func (tl *TodoList) ResolveConflicts(keepOurs map[int]bool) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.writeMerged(tl.saveSnapshot, func(conflict MergeConflict) bool {
+		return keepOurs[conflict.ID]
+	})
+}
+
+// conflictVersionText describes one version of a conflicting todo
+func conflictVersionText(todo *Todo) string {
+	if todo == nil {
+		return "(đã xóa)"
+	}
+
+	parts := []string{todo.Description}
+	switch {
+	case todo.InTrash():
+		parts = append(parts, "🗑️ trong thùng rác")
+	case todo.Completed:
+		parts = append(parts, "✅ hoàn thành")
+	}
+	if todo.Priority != PriorityNone {
+		parts = append(parts, todo.Priority.Label())
+	}
+	if !todo.DueAt.IsZero() {
+		parts = append(parts, "🗓️ "+todo.DueAt.Format("02/01/2006"))
+	}
+	if len(todo.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(todo.Tags, " #"))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// showMergeConflictDialog lets the user choose, for each todo changed both
+// in the app and on disk, which version to keep
+func (app *TodoApp) showMergeConflictDialog(conflict *MergeConflictError) {
+	list := app.listFor(conflict.Project)
+
+	choices := make(map[int]*widget.RadioGroup, len(conflict.Conflicts))
+	content := container.NewVBox(widget.NewLabel("Các công việc sau đã bị thay đổi khác nhau trong ứng dụng và trên đĩa.\nChọn bản cần giữ, các thay đổi khác đã được gộp tự động."))
+	for _, c := range conflict.Conflicts {
+		title := widget.NewLabel(fmt.Sprintf("#%d", c.ID))
+		if c.Base != nil {
+			title.SetText(fmt.Sprintf("#%d %s", c.ID, c.Base.Description))
+		}
+		title.TextStyle = fyne.TextStyle{Bold: true}
+
+		choice := widget.NewRadioGroup([]string{keepOursOption, keepTheirsOption}, nil)
+		choice.SetSelected(keepOursOption)
+		choices[c.ID] = choice
+
+		content.Add(widget.NewSeparator())
+		content.Add(title)
+		content.Add(widget.NewLabel("Của tôi: " + conflictVersionText(c.Ours)))
+		content.Add(widget.NewLabel("Trên đĩa: " + conflictVersionText(c.Theirs)))
+		content.Add(choice)
+	}
+
+	d := dialog.NewCustomConfirm("🔀 Xung đột dữ liệu", "Áp dụng", keepTheirsOption, container.NewVScroll(content), func(apply bool) {
+		keepOurs := make(map[int]bool)
+		if apply {
+			for id, choice := range choices {
+				keepOurs[id] = choice.Selected == keepOursOption
+			}
+		}
+		err := list.ResolveConflicts(keepOurs)
+		app.refreshAllLists()
+		if err != nil {
+			app.showListError(err)
+			return
+		}
+		app.snackbar.Show("Đã gộp các thay đổi trên đĩa", "", nil)
+	}, app.window)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMergeTodos(t *testing.T) {
+	a := Todo{ID: 1, Description: "a"}
+	aOurs := Todo{ID: 1, Description: "a của tôi"}
+	aTheirs := Todo{ID: 1, Description: "a trên đĩa"}
+	b := Todo{ID: 2, Description: "b"}
+	bDone := Todo{ID: 2, Description: "b", Completed: true}
+	cOurs := Todo{ID: 3, Description: "c của tôi"}
+	cTheirs := Todo{ID: 3, Description: "c trên đĩa"}
+	childOurs := Todo{ID: 4, Description: "con", ParentID: 3}
+
+	tests := []struct {
+		name               string
+		base, ours, theirs []Todo
+		want               []Todo
+		conflicts          []int
+		renamed            map[int]int
+	}{
+		{
+			name: "unchanged",
+			base: []Todo{a, b}, ours: []Todo{a, b}, theirs: []Todo{a, b},
+			want: []Todo{a, b},
+		},
+		{
+			name: "changed on each side",
+			base: []Todo{a, b}, ours: []Todo{aOurs, b}, theirs: []Todo{a, bDone},
+			want: []Todo{aOurs, bDone},
+		},
+		{
+			name: "same change on both sides",
+			base: []Todo{a}, ours: []Todo{aOurs}, theirs: []Todo{aOurs},
+			want: []Todo{aOurs},
+		},
+		{
+			name: "deleted on one side",
+			base: []Todo{a, b}, ours: []Todo{b}, theirs: []Todo{a, b},
+			want: []Todo{b},
+		},
+		{
+			name: "changed on both sides",
+			base: []Todo{a}, ours: []Todo{aOurs}, theirs: []Todo{aTheirs},
+			want:      []Todo{aTheirs},
+			conflicts: []int{1},
+		},
+		{
+			name: "changed here, deleted on disk",
+			base: []Todo{a, b}, ours: []Todo{aOurs, b}, theirs: []Todo{b},
+			want:      []Todo{b},
+			conflicts: []int{1},
+		},
+		{
+			name: "added on both sides with the same ID",
+			base: []Todo{a}, ours: []Todo{a, cOurs, childOurs}, theirs: []Todo{a, cTheirs},
+			want:    []Todo{a, cTheirs, {ID: 4, Description: "con", ParentID: 5}, {ID: 5, Description: "c của tôi"}},
+			renamed: map[int]int{3: 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicts, renamed := mergeTodos(test.base, test.ours, test.theirs)
+			if !sameTodos(merged, test.want) {
+				t.Errorf("merged = %+v, want %+v", merged, test.want)
+			}
+			var ids []int
+			for _, conflict := range conflicts {
+				ids = append(ids, conflict.ID)
+			}
+			if !slices.Equal(ids, test.conflicts) {
+				t.Errorf("conflicts = %v, want %v", ids, test.conflicts)
+			}
+			if len(renamed) > 0 || len(test.renamed) > 0 {
+				if !maps.Equal(renamed, test.renamed) {
+					t.Errorf("renamed = %v, want %v", renamed, test.renamed)
+				}
+			}
+		})
+	}
+}
+
+// openTwice opens the main list of one store twice, like two windows of
+// the app
+func openTwice(t *testing.T) (*TodoList, *TodoList) {
+	dir := t.TempDir()
+	store := NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project"))
+	return NewTodoList(store, ""), NewTodoList(store, "")
+}
+
+func TestAddAfterMerge(t *testing.T) {
+	ours, theirs := openTwice(t)
+	if err := theirs.AddTodo("trên đĩa"); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := ours.AddTodoItem(Todo{Description: "của tôi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 2 {
+		t.Fatalf("added todo has ID %d, want the new ID 2", added.ID)
+	}
+	if history := ours.History(1); len(history) != 1 || history[0].Todo.Description != "trên đĩa" {
+		t.Errorf("history of the todo on disk = %+v", history)
+	}
+
+	// Undo of the add
+	if err := ours.RemoveTodo(added.ID); err != nil {
+		t.Fatal(err)
+	}
+	todos := NewTodoList(ours.store, "").AllTodos()
+	if len(todos) != 1 || todos[0].Description != "trên đĩa" {
+		t.Errorf("after removing the added todo: %+v", todos)
+	}
+}
+
+func TestImportAfterMerge(t *testing.T) {
+	ours, theirs := openTwice(t)
+	for _, description := range []string{"x", "y"} {
+		if err := theirs.AddTodo(description); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	added, err := ours.ImportTodos([]Todo{
+		{ID: 1, Description: "cha"},
+		{ID: 2, Description: "con", ParentID: 1},
+		{ID: 3, Description: "khác"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewTodoList(ours.store, "")
+	all := reloaded.AllTodos()
+	if len(all) != 5 {
+		t.Fatalf("list after import = %+v, want 5 todos", all)
+	}
+	ids := map[int]bool{}
+	for _, todo := range all {
+		if ids[todo.ID] {
+			t.Fatalf("duplicate ID %d in %+v", todo.ID, all)
+		}
+		ids[todo.ID] = true
+	}
+	for _, todo := range added {
+		got, ok := reloaded.GetTodo(todo.ID)
+		if !ok || got.Description != todo.Description || got.ParentID != todo.ParentID {
+			t.Errorf("imported %+v is stored as %+v", todo, got)
+		}
+	}
+	if parent, _ := reloaded.GetTodo(added[1].ParentID); parent.Description != "cha" {
+		t.Errorf("parent of the subtask is %+v", parent)
+	}
+
+	if err := removeImported(ours, added); err != nil {
+		t.Fatal(err)
+	}
+	if todos := NewTodoList(ours.store, "").AllTodos(); len(todos) != 2 {
+		t.Errorf("after undoing the import: %+v", todos)
+	}
+}
+
+func TestMergeConflictKeepsChanges(t *testing.T) {
+	ours, theirs := openTwice(t)
+	if err := ours.AddTodo("chung"); err != nil {
+		t.Fatal(err)
+	}
+	theirs.LoadFromFile()
+	if _, err := theirs.UpdateTodo(1, TodoUpdate{Description: ptr("trên đĩa")}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ours.UpdateTodo(1, TodoUpdate{Description: ptr("của tôi")})
+	var conflict *MergeConflictError
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 {
+		t.Fatalf("UpdateTodo = %v, want a merge conflict", err)
+	}
+	if err := ours.ResolveConflicts(map[int]bool{1: true}); err != nil {
+		t.Fatal(err)
+	}
+	if todo, _ := NewTodoList(ours.store, "").GetTodo(1); todo.Description != "của tôi" {
+		t.Errorf("resolved todo = %+v", todo)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// Siblings returns the todos that share a parent, outside the trash, in
+// manual order
+func (tl *TodoList) Siblings(parentID int) []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.siblings(parentID)
+}
+
+// siblings is Siblings with the mutex held
+func (tl *TodoList) siblings(parentID int) []Todo {
+	var siblings []Todo
+	for _, todo := range tl.getTodos() {
+		if todo.ParentID == parentID {
+			siblings = append(siblings, todo)
+		}
+	}
+	sortTodos(siblings, SortManual)
+	return siblings
+}
+
+// MoveTodo moves a todo to the place of targetID in the manual order of
+// their parent, shifting the todos in between. Both todos must share a
+// parent. The siblings are renumbered from 1 so that the order is kept.
+func (tl *TodoList) MoveTodo(id, targetID int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+	j := tl.index(targetID)
+	if j < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", targetID)
+	}
+	if tl.todos[i].ParentID != tl.todos[j].ParentID {
+		return fmt.Errorf("chỉ có thể di chuyển giữa các công việc cùng cấp")
+	}
+	if id == targetID {
+		return nil
+	}
+
+	siblings := tl.siblings(tl.todos[i].ParentID)
+	from, to := -1, -1
+	for k, sibling := range siblings {
+		switch sibling.ID {
+		case id:
+			from = k
+		case targetID:
+			to = k
+		}
+	}
+
+	moved := siblings[from]
+	siblings = append(siblings[:from], siblings[from+1:]...)
+	siblings = append(siblings[:to], append([]Todo{moved}, siblings[to:]...)...)
+
+	for k, sibling := range siblings {
+		n := tl.index(sibling.ID)
+		if tl.todos[n].Position == k+1 {
+			continue
+		}
+		tl.todos[n].Position = k + 1
+		todo := tl.todos[n]
+		if err := tl.record(OpEdit, todo.ID, &todo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Priority is the importance of a todo; higher values are more urgent
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// priorityNames are the persisted names of the priorities, by value
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// priorityLabels are the names shown in the interface, by value
+var priorityLabels = []string{"Không", "Thấp", "Trung bình", "Cao", "Khẩn cấp"}
+
+// Valid reports whether p is one of the known priorities
+func (p Priority) Valid() bool {
+	return p >= PriorityNone && p <= PriorityUrgent
+}
+
+// String returns the persisted name of the priority
+func (p Priority) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// Label returns the name of the priority shown in the interface
+func (p Priority) Label() string {
+	if !p.Valid() {
+		return p.String()
+	}
+	return priorityLabels[p]
+}
+
+// ParsePriority parses a persisted priority name
+func ParsePriority(name string) (Priority, error) {
+	for i, n := range priorityNames {
+		if strings.EqualFold(name, n) {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("độ ưu tiên không hợp lệ: %q", name)
+}
+
+// ParsePriorityLabel returns the priority shown with the given label
+func ParsePriorityLabel(label string) Priority {
+	for i, l := range priorityLabels {
+		if l == label {
+			return Priority(i)
+		}
+	}
+	return PriorityNone
+}
+
+// MarshalText stores the priority by name in JSON
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText reads a priority stored by name
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// SetPriority changes the priority of a todo
+func (tl *TodoList) SetPriority(id int, priority Priority) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if !priority.Valid() {
+		return fmt.Errorf("độ ưu tiên không hợp lệ: %d", priority)
+	}
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	tl.todos[i].Priority = priority
+	todo := tl.todos[i]
+	return tl.record(OpEdit, id, &todo)
+}
+
+// Sort modes of a list
+const (
+	SortCreated  = "created"  // Newest first
+	SortPriority = "priority" // Most urgent first
+	SortDue      = "due"      // Earliest due date first
+	SortAlpha    = "alpha"    // Alphabetical by description
+	SortManual   = "manual"   // Order set by moving todos; unmoved todos first, newest first
+)
+
+// sortModes are the available sort modes, in the order they are offered
+var sortModes = []string{SortManual, SortCreated, SortPriority, SortDue, SortAlpha}
+
+// sortModeLabel returns the name of a sort mode shown in the interface
+func sortModeLabel(mode string) string {
+	switch mode {
+	case SortCreated:
+		return "Mới nhất"
+	case SortPriority:
+		return "Độ ưu tiên"
+	case SortDue:
+		return "Hạn chót"
+	case SortAlpha:
+		return "A → Z"
+	case SortManual:
+		return "Thủ công"
+	}
+	return mode
+}
+
+// sortTodos orders todos in place by the given mode. Ties are broken by
+// creation order, newest first.
+func sortTodos(todos []Todo, mode string) {
+	newer := func(a, b Todo) bool {
+		return a.ID > b.ID
+	}
+
+	var less func(a, b Todo) bool
+	switch mode {
+	case SortPriority:
+		less = func(a, b Todo) bool {
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return newer(a, b)
+		}
+	case SortDue:
+		less = func(a, b Todo) bool {
+			if a.DueAt.IsZero() != b.DueAt.IsZero() {
+				return !a.DueAt.IsZero()
+			}
+			if !a.DueAt.Equal(b.DueAt) {
+				return a.DueAt.Before(b.DueAt)
+			}
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return newer(a, b)
+		}
+	case SortAlpha:
+		// Orders descriptions the way a Vietnamese reader expects; a
+		// collator is not safe for concurrent use
+		collator := collate.New(language.Vietnamese, collate.IgnoreCase)
+		less = func(a, b Todo) bool {
+			if c := collator.CompareString(a.Description, b.Description); c != 0 {
+				return c < 0
+			}
+			return newer(a, b)
+		}
+	case SortManual:
+		less = func(a, b Todo) bool {
+			if a.Position != b.Position {
+				return a.Position < b.Position
+			}
+			return newer(a, b)
+		}
+	default:
+		less = newer
+	}
+
+	sort.SliceStable(todos, func(i, j int) bool {
+		return less(todos[i], todos[j])
+	})
+}
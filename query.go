@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Query is a parsed search query such as
+//
+//	sửa is:open created:>2025-10-01 project:wee
+//
+// Every term must match. A term is one of:
+//
+//	word or "some words"      text in the description, ignoring case and accents
+//	is:open|done|overdue|today|upcoming|repeat|subtask
+//	created:, start:, due:, completed:  a date with an optional comparison
+//	                          (>, >=, <, <=, =), e.g. due:<=today, or none / any
+//	tag:work or #work         carries the tag
+//	priority:high             priority, also with a comparison, e.g. priority:>=medium
+//	project:name              project name contains name; project:none is the main list
+//
+// A term starting with "-" is negated, e.g. -is:done or -#home. A word of
+// letters before ":" must be a key; words such as 10:30 or URLs are text.
+type Query struct {
+	terms []queryTerm
+}
+
+// queryTerm is one condition of a query
+type queryTerm struct {
+	negate bool
+	match  func(todo Todo, project string) bool
+}
+
+// queryKeys are the keys accepted before ":", listed in parse errors
+const queryKeys = "is, created, start, due, completed, tag, priority, project"
+
+// ParseQuery parses a search query. An empty query matches every todo.
+func ParseQuery(text string) (Query, error) {
+	tokens, err := splitQuery(text)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var q Query
+	for _, token := range tokens {
+		term, err := parseQueryTerm(token)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// IsZero reports whether the query has no terms
+func (q Query) IsZero() bool {
+	return len(q.terms) == 0
+}
+
+// Match reports whether a todo of the given project ("" for the main list)
+// matches every term of the query
+func (q Query) Match(todo Todo, project string) bool {
+	for _, term := range q.terms {
+		if term.match(todo, project) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the todos of a project that match the query
+func (q Query) Filter(todos []Todo, project string) []Todo {
+	var matched []Todo
+	for _, todo := range todos {
+		if q.Match(todo, project) {
+			matched = append(matched, todo)
+		}
+	}
+	return matched
+}
+
+// queryToken is a word of a query; quoted marks text that was inside
+// double quotes, so that it is never read as key:value
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query into words. Double quotes group words, also
+// after a key, e.g. project:"việc nhà".
+func splitQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	var b strings.Builder
+	inQuotes, quoted, started := false, false, false
+
+	flush := func() {
+		if started {
+			tokens = append(tokens, queryToken{text: b.String(), quoted: quoted})
+		}
+		b.Reset()
+		quoted, started = false, false
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			// Only a word that starts with a quote is plain text
+			if !started {
+				quoted = true
+			}
+			started = true
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			b.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("thiếu dấu ngoặc kép đóng trong truy vấn")
+	}
+	flush()
+	return tokens, nil
+}
+
+// parseQueryTerm parses a single word of a query
+func parseQueryTerm(token queryToken) (queryTerm, error) {
+	text := token.text
+	negate := false
+	if !token.quoted && len(text) > 1 && strings.HasPrefix(text, "-") {
+		negate = true
+		text = text[1:]
+	}
+
+	if token.quoted {
+		return queryTerm{negate: negate, match: matchText(text)}, nil
+	}
+	if strings.HasPrefix(text, "#") {
+		tag := normalizeTag(text)
+		if tag == "" {
+			return queryTerm{}, fmt.Errorf("thiếu tên thẻ sau \"#\"")
+		}
+		return queryTerm{negate: negate, match: matchTag(tag)}, nil
+	}
+
+	key, value, ok := strings.Cut(text, ":")
+	if !ok || !isQueryKey(key) || strings.HasPrefix(value, "//") {
+		return queryTerm{negate: negate, match: matchText(text)}, nil
+	}
+	key = strings.ToLower(key)
+	if value == "" {
+		return queryTerm{}, fmt.Errorf("thiếu giá trị sau %q", key+":")
+	}
+
+	var match func(Todo, string) bool
+	var err error
+	switch key {
+	case "is":
+		match, err = parseStatus(value)
+	case "created":
+		match, err = parseDateTerm(key, value, func(t Todo) time.Time { return t.CreatedAt })
+	case "start":
+		match, err = parseDateTerm(key, value, func(t Todo) time.Time { return t.StartAt })
+	case "due":
+		match, err = parseDateTerm(key, value, func(t Todo) time.Time { return t.DueAt })
+	case "completed":
+		match, err = parseDateTerm(key, value, func(t Todo) time.Time { return t.CompletedAt })
+	case "tag":
+		match = matchTag(normalizeTag(value))
+	case "priority":
+		match, err = parsePriorityTerm(value)
+	case "project":
+		match = matchProject(value)
+	default:
+		err = fmt.Errorf("từ khóa không hợp lệ %q (dùng: %s; đặt trong ngoặc kép để tìm chữ)", key, queryKeys)
+	}
+	if err != nil {
+		return queryTerm{}, err
+	}
+	return queryTerm{negate: negate, match: match}, nil
+}
+
+// isQueryKey reports whether text looks like a key, i.e. only letters, so
+// that words such as "10:30" stay plain text
+func isQueryKey(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// foldText lowercases text and removes Vietnamese accents so that "sua"
+// finds "Sửa"
+func foldText(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.ToLower(text))
+	if err != nil {
+		folded = strings.ToLower(text)
+	}
+	return strings.ReplaceAll(folded, "đ", "d")
+}
+
+// matchText matches todos whose description contains text
+func matchText(text string) func(Todo, string) bool {
+	needle := foldText(text)
+	return func(todo Todo, _ string) bool {
+		return strings.Contains(foldText(todo.Description), needle)
+	}
+}
+
+// matchTag matches todos carrying a tag
+func matchTag(tag string) func(Todo, string) bool {
+	return func(todo Todo, _ string) bool {
+		return todo.HasTag(tag)
+	}
+}
+
+// matchProject matches todos of projects whose name contains name;
+// "none" matches the main list
+func matchProject(name string) func(Todo, string) bool {
+	if strings.EqualFold(name, "none") {
+		return func(_ Todo, project string) bool {
+			return project == ""
+		}
+	}
+	needle := foldText(name)
+	return func(_ Todo, project string) bool {
+		return project != "" && strings.Contains(foldText(project), needle)
+	}
+}
+
+// parseStatus parses the value of is:
+func parseStatus(value string) (func(Todo, string) bool, error) {
+	switch strings.ToLower(value) {
+	case "open":
+		return func(t Todo, _ string) bool { return !t.Completed }, nil
+	case "done":
+		return func(t Todo, _ string) bool { return t.Completed }, nil
+	case "overdue":
+		return func(t Todo, _ string) bool { return t.DueStatusAt(time.Now()) == DueOverdue }, nil
+	case "today":
+		return func(t Todo, _ string) bool { return t.DueStatusAt(time.Now()) == DueToday }, nil
+	case "upcoming":
+		return func(t Todo, _ string) bool { return t.DueStatusAt(time.Now()) == DueUpcoming }, nil
+	case "repeat":
+		return func(t Todo, _ string) bool { return !t.Recurrence.IsZero() }, nil
+	case "subtask":
+		return func(t Todo, _ string) bool { return t.ParentID != 0 }, nil
+	}
+	return nil, fmt.Errorf("trạng thái không hợp lệ %q (dùng: open, done, overdue, today, upcoming, repeat, subtask)", value)
+}
+
+// splitComparison splits a leading >, >=, <, <= or = from value
+func splitComparison(value string) (op, rest string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+// compare applies a comparison operator to the result of a three-way
+// comparison
+func compare(op string, c int) bool {
+	switch op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return c == 0
+}
+
+// parseQueryDate parses a day as 2006-01-02, 02/01/2006, today, tomorrow
+// or yesterday
+func parseQueryDate(value string) (time.Time, error) {
+	today := startOfDay(time.Now())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006", "2/1/2006"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("ngày không hợp lệ %q (dùng 2025-10-01, 01/10/2025, today, tomorrow hoặc yesterday)", value)
+}
+
+// parseDateTerm parses the value of a date key such as due:<=today
+func parseDateTerm(key, value string, field func(Todo) time.Time) (func(Todo, string) bool, error) {
+	switch strings.ToLower(value) {
+	case "none":
+		return func(t Todo, _ string) bool { return field(t).IsZero() }, nil
+	case "any":
+		return func(t Todo, _ string) bool { return !field(t).IsZero() }, nil
+	}
+
+	op, rest := splitComparison(value)
+	day, err := parseQueryDate(rest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", key, err)
+	}
+	return func(t Todo, _ string) bool {
+		at := field(t)
+		if at.IsZero() {
+			return false
+		}
+		return compare(op, startOfDay(at.Local()).Compare(day))
+	}, nil
+}
+
+// parsePriorityTerm parses the value of priority:, e.g. high or >=medium
+func parsePriorityTerm(value string) (func(Todo, string) bool, error) {
+	op, rest := splitComparison(value)
+	priority, err := ParsePriority(rest)
+	if err != nil {
+		return nil, fmt.Errorf("độ ưu tiên không hợp lệ %q (dùng: none, low, medium, high, urgent)", rest)
+	}
+	return func(t Todo, _ string) bool {
+		return compare(op, int(t.Priority)-int(priority))
+	}, nil
+}
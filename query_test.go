@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	today := startOfDay(time.Now())
+	todos := []Todo{
+		{ID: 1, Description: "Sửa xe đạp", Tags: []string{"nhà"}, Priority: PriorityHigh, CreatedAt: time.Date(2025, 10, 5, 8, 0, 0, 0, time.Local)},
+		{ID: 2, Description: "Họp lúc 10:30", DueAt: today.AddDate(0, 0, -1), CreatedAt: time.Date(2025, 9, 1, 8, 0, 0, 0, time.Local)},
+		{ID: 3, Description: "Đọc ghichu:abc", Completed: true, CompletedAt: today, ParentID: 1},
+		{ID: 4, Description: "Mua sách ở https://sach.vn", DueAt: today.AddDate(0, 0, 3), Priority: PriorityLow},
+	}
+
+	tests := []struct {
+		query   string
+		project string
+		want    []int
+	}{
+		{"", "", []int{1, 2, 3, 4}},
+		{"sua", "", []int{1}},
+		{"XE DAP", "", []int{1}},
+		{`"xe đạp"`, "", []int{1}},
+		{`"xe sửa"`, "", nil},
+		{"10:30", "", []int{2}},
+		{`"ghichu:abc"`, "", []int{3}},
+		{"https://sach.vn", "", []int{4}},
+		{`"is:done"`, "", nil},
+		{"is:done", "", []int{3}},
+		{"-is:done", "", []int{1, 2, 4}},
+		{"is:overdue", "", []int{2}},
+		{"is:upcoming", "", []int{4}},
+		{"is:subtask", "", []int{3}},
+		{"#nhà", "", []int{1}},
+		{"tag:NHÀ", "", []int{1}},
+		{"-#nhà", "", []int{2, 3, 4}},
+		{"priority:>=medium", "", []int{1}},
+		{"priority:low", "", []int{4}},
+		{"created:>2025-09-30", "", []int{1}},
+		{"created:<=01/09/2025", "", []int{2}},
+		{"due:none", "", []int{1, 3}},
+		{"due:>today", "", []int{4}},
+		{"completed:today", "", []int{3}},
+		{"project:none", "", []int{1, 2, 3, 4}},
+		{"project:none", "Việc nhà", nil},
+		{"project:viec", "Việc nhà", []int{1, 2, 3, 4}},
+		{`project:"việc nhà" sách`, "Việc nhà", []int{4}},
+	}
+
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", test.query, err)
+			continue
+		}
+		var got []int
+		for _, todo := range q.Filter(todos, test.project) {
+			got = append(got, todo.ID)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("ParseQuery(%q) in %q matches %v, want %v", test.query, test.project, got, test.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		`"xe đạp`,
+		"is:",
+		"is:later",
+		"due:soon",
+		"priority:max",
+		"#",
+		"prioirty:high",
+		"stauts:open",
+		"is:active",
+		"is:completed",
+		"is:repeating",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded", query)
+		}
+	}
+
+	// An unknown key names the valid ones
+	if _, err := ParseQuery("prioirty:high"); err == nil || !strings.Contains(err.Error(), "priority") {
+		t.Errorf("ParseQuery(%q) = %v, want an error listing the keys", "prioirty:high", err)
+	}
+}
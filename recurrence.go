@@ -0,0 +1,418 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"todoapp/lunar"
+)
+
+// Recurrence frequencies
+const (
+	RepeatDaily   = "DAILY"   // Every Interval days from the due date
+	RepeatWeekly  = "WEEKLY"  // On Weekdays, every Interval weeks
+	RepeatMonthly = "MONTHLY" // On MonthDays, every Interval months
+	RepeatAfter   = "AFTER"   // Interval days after the todo was completed
+
+	RepeatLunarMonthly = "LUNARMONTHLY" // On lunar MonthDays, every Interval lunar months
+	RepeatLunarYearly  = "LUNARYEARLY"  // On lunar MonthDays of Months, every Interval lunar years
+)
+
+// maxRecurrenceSteps bounds the search for the next occurrence
+const maxRecurrenceSteps = 5000
+
+// Recurrence is the repeat rule of a todo. It is persisted in an RRULE-like
+// form, e.g. "FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,WE". The lunar frequencies
+// follow the Vietnamese lunar calendar (âm lịch).
+type Recurrence struct {
+	Freq      string
+	Interval  int            // Every Interval days, weeks, months or years; at least 1
+	Weekdays  []time.Weekday // WEEKLY: days of the week
+	MonthDays []int          // MONTHLY: days of the month, 1-31; lunar: 1-30
+	Months    []int          // LUNARYEARLY: lunar months, 1-12
+}
+
+// rruleWeekdays are the RRULE names of the weekdays, by time.Weekday
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// weekdayLabels are the Vietnamese short names of the weekdays
+var weekdayLabels = []string{"CN", "T2", "T3", "T4", "T5", "T6", "T7"}
+
+// IsZero reports whether the todo does not repeat
+func (r Recurrence) IsZero() bool {
+	return r.Freq == ""
+}
+
+// Validate checks the rule and fills in defaults
+func (r *Recurrence) Validate() error {
+	if r.IsZero() {
+		return nil
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("chu kỳ lặp phải lớn hơn 0")
+	}
+
+	switch r.Freq {
+	case RepeatDaily, RepeatAfter:
+	case RepeatWeekly:
+		if len(r.Weekdays) == 0 {
+			return fmt.Errorf("chọn ít nhất một ngày trong tuần")
+		}
+		sort.Slice(r.Weekdays, func(i, j int) bool { return r.Weekdays[i] < r.Weekdays[j] })
+	case RepeatMonthly, RepeatLunarMonthly, RepeatLunarYearly:
+		maxDay := 31
+		if r.Freq != RepeatMonthly {
+			maxDay = 30
+		}
+		if len(r.MonthDays) == 0 {
+			return fmt.Errorf("chọn ít nhất một ngày trong tháng")
+		}
+		for _, day := range r.MonthDays {
+			if day < 1 || day > maxDay {
+				return fmt.Errorf("ngày trong tháng không hợp lệ: %d", day)
+			}
+		}
+		sort.Ints(r.MonthDays)
+
+		if r.Freq == RepeatLunarYearly {
+			if len(r.Months) == 0 {
+				return fmt.Errorf("chọn ít nhất một tháng âm lịch")
+			}
+			for _, month := range r.Months {
+				if month < 1 || month > 12 {
+					return fmt.Errorf("tháng âm lịch không hợp lệ: %d", month)
+				}
+			}
+			sort.Ints(r.Months)
+		}
+	default:
+		return fmt.Errorf("kiểu lặp không hợp lệ: %q", r.Freq)
+	}
+	return nil
+}
+
+// String returns the persisted form of the rule
+func (r Recurrence) String() string {
+	if r.IsZero() {
+		return ""
+	}
+
+	parts := []string{"FREQ=" + r.Freq, "INTERVAL=" + strconv.Itoa(max(r.Interval, 1))}
+	if len(r.Weekdays) > 0 {
+		var days []string
+		for _, day := range r.Weekdays {
+			days = append(days, rruleWeekdays[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.MonthDays) > 0 {
+		var days []string
+		for _, day := range r.MonthDays {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.Months) > 0 {
+		var months []string
+		for _, month := range r.Months {
+			months = append(months, strconv.Itoa(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseRecurrence parses a rule in the persisted form
+func ParseRecurrence(s string) (Recurrence, error) {
+	var r Recurrence
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return Recurrence{}, fmt.Errorf("chu kỳ lặp không hợp lệ: %q", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, name := range strings.Split(value, ",") {
+				day := -1
+				for i, n := range rruleWeekdays {
+					if strings.EqualFold(name, n) {
+						day = i
+					}
+				}
+				if day < 0 {
+					return Recurrence{}, fmt.Errorf("ngày trong tuần không hợp lệ: %q", name)
+				}
+				r.Weekdays = append(r.Weekdays, time.Weekday(day))
+			}
+		case "BYMONTHDAY":
+			for _, field := range strings.Split(value, ",") {
+				day, err := strconv.Atoi(field)
+				if err != nil {
+					return Recurrence{}, fmt.Errorf("ngày trong tháng không hợp lệ: %q", field)
+				}
+				r.MonthDays = append(r.MonthDays, day)
+			}
+		case "BYMONTH":
+			for _, field := range strings.Split(value, ",") {
+				month, err := strconv.Atoi(field)
+				if err != nil {
+					return Recurrence{}, fmt.Errorf("tháng không hợp lệ: %q", field)
+				}
+				r.Months = append(r.Months, month)
+			}
+		}
+	}
+
+	if r.Freq == "" {
+		return Recurrence{}, fmt.Errorf("quy tắc lặp thiếu FREQ: %q", s)
+	}
+	if err := r.Validate(); err != nil {
+		return Recurrence{}, err
+	}
+	return r, nil
+}
+
+// MarshalText stores the rule in its persisted form in JSON
+func (r Recurrence) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads a rule stored in its persisted form
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*r = Recurrence{}
+		return nil
+	}
+	parsed, err := ParseRecurrence(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Describe returns the rule in words, for the card
+func (r Recurrence) Describe() string {
+	interval := max(r.Interval, 1)
+	switch r.Freq {
+	case RepeatDaily:
+		if interval == 1 {
+			return "Hằng ngày"
+		}
+		return fmt.Sprintf("Mỗi %d ngày", interval)
+	case RepeatWeekly:
+		var days []string
+		for _, day := range r.Weekdays {
+			days = append(days, weekdayLabels[day])
+		}
+		if interval == 1 {
+			return "Hằng tuần: " + strings.Join(days, ", ")
+		}
+		return fmt.Sprintf("Mỗi %d tuần: %s", interval, strings.Join(days, ", "))
+	case RepeatMonthly:
+		var days []string
+		for _, day := range r.MonthDays {
+			days = append(days, strconv.Itoa(day))
+		}
+		if interval == 1 {
+			return "Hằng tháng: ngày " + strings.Join(days, ", ")
+		}
+		return fmt.Sprintf("Mỗi %d tháng: ngày %s", interval, strings.Join(days, ", "))
+	case RepeatAfter:
+		return fmt.Sprintf("%d ngày sau khi hoàn thành", interval)
+	case RepeatLunarMonthly:
+		var days []string
+		for _, day := range r.MonthDays {
+			days = append(days, lunar.DayName(day))
+		}
+		if interval == 1 {
+			return "Âm lịch hằng tháng: " + strings.Join(days, ", ")
+		}
+		return fmt.Sprintf("Âm lịch mỗi %d tháng: %s", interval, strings.Join(days, ", "))
+	case RepeatLunarYearly:
+		var dates []string
+		for _, month := range r.Months {
+			for _, day := range r.MonthDays {
+				dates = append(dates, lunar.Date{Month: month, Day: day}.Describe())
+			}
+		}
+		if interval == 1 {
+			return "Âm lịch hằng năm: " + strings.Join(dates, ", ")
+		}
+		return fmt.Sprintf("Âm lịch mỗi %d năm: %s", interval, strings.Join(dates, ", "))
+	}
+	return r.String()
+}
+
+// Next returns the due date of the next occurrence. due is the due date of
+// the current occurrence (zero if none) and completedAt when it was done.
+// Calendar rules are counted from the due date, or from the completion day
+// if there is none, and skip occurrences that are already in the past.
+func (r Recurrence) Next(due, completedAt time.Time) time.Time {
+	today := startOfDay(completedAt)
+	if r.Freq == RepeatAfter {
+		return today.AddDate(0, 0, max(r.Interval, 1))
+	}
+
+	base := today
+	if !due.IsZero() {
+		base = startOfDay(due)
+	}
+
+	next := r.after(base, base)
+	for i := 0; next.Before(today.AddDate(0, 0, 1)) && i < maxRecurrenceSteps; i++ {
+		next = r.after(base, next)
+	}
+	return next
+}
+
+// First returns the first occurrence of the rule on or after from
+func (r Recurrence) First(from time.Time) time.Time {
+	from = startOfDay(from)
+	if r.matches(from, from) {
+		return from
+	}
+	return r.after(from, from)
+}
+
+// after returns the first occurrence strictly after day; anchor is the
+// occurrence the intervals are counted from
+func (r Recurrence) after(anchor, day time.Time) time.Time {
+	for i := 0; i < maxRecurrenceSteps; i++ {
+		day = day.AddDate(0, 0, 1)
+		if r.matches(anchor, day) {
+			return day
+		}
+	}
+	return day
+}
+
+// matches reports whether day is an occurrence of the rule counted from
+// anchor
+func (r Recurrence) matches(anchor, day time.Time) bool {
+	interval := max(r.Interval, 1)
+	switch r.Freq {
+	case RepeatDaily, RepeatAfter:
+		days := int(day.Sub(anchor).Hours()+12) / 24
+		return days%interval == 0
+	case RepeatWeekly:
+		weeks := int(startOfWeek(day).Sub(startOfWeek(anchor)).Hours()+12) / (24 * 7)
+		if weeks%interval != 0 {
+			return false
+		}
+		for _, weekday := range r.Weekdays {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+	case RepeatMonthly:
+		months := (day.Year()-anchor.Year())*12 + int(day.Month()-anchor.Month())
+		if months%interval != 0 {
+			return false
+		}
+		lastDay := daysInMonth(day.Year(), day.Month())
+		for _, monthDay := range r.MonthDays {
+			// Days past the end of a short month fall on its last day
+			if min(monthDay, lastDay) == day.Day() {
+				return true
+			}
+		}
+	case RepeatLunarMonthly:
+		if lunar.MonthsBetween(anchor, day)%interval != 0 {
+			return false
+		}
+		return r.matchesLunarDay(lunar.FromSolar(day))
+	case RepeatLunarYearly:
+		date := lunar.FromSolar(day)
+		if date.Leap || (date.Year-lunar.FromSolar(anchor).Year)%interval != 0 {
+			return false
+		}
+		for _, month := range r.Months {
+			if month == date.Month {
+				return r.matchesLunarDay(date)
+			}
+		}
+	}
+	return false
+}
+
+// matchesLunarDay reports whether a lunar date falls on one of MonthDays.
+// Day 30 falls on the last day of a 29-day month.
+func (r Recurrence) matchesLunarDay(date lunar.Date) bool {
+	lastDay, err := lunar.DaysInMonth(date.Year, date.Month, date.Leap)
+	if err != nil {
+		return false
+	}
+	for _, monthDay := range r.MonthDays {
+		if min(monthDay, lastDay) == date.Day {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfWeek returns the Monday of the week day falls in
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return startOfDay(day).AddDate(0, 0, -offset)
+}
+
+// daysInMonth returns the number of days of a month
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local).Day()
+}
+
+// SetRecurrence sets or clears (with a zero rule) the repeat rule of a todo.
+// A repeating todo without a due date gets its first occurrence as due date.
+func (tl *TodoList) SetRecurrence(id int, rule Recurrence) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	tl.todos[i].Recurrence = rule
+	if !rule.IsZero() && rule.Freq != RepeatAfter && tl.todos[i].DueAt.IsZero() {
+		tl.todos[i].DueAt = rule.First(time.Now())
+	}
+	todo := tl.todos[i]
+	return tl.record(OpEdit, id, &todo)
+}
+
+// nextOccurrence returns the todo created when a repeating todo is
+// completed at completedAt. The start date keeps its distance to the due date.
+func nextOccurrence(todo Todo, completedAt time.Time) Todo {
+	next := Todo{
+		Description: todo.Description,
+		Priority:    todo.Priority,
+		Tags:        todo.Tags,
+		ParentID:    todo.ParentID,
+		Recurrence:  todo.Recurrence,
+		DueAt:       todo.Recurrence.Next(todo.DueAt, completedAt),
+	}
+	if !todo.StartAt.IsZero() && !todo.DueAt.IsZero() {
+		days := int(startOfDay(todo.DueAt).Sub(startOfDay(todo.StartAt)).Hours()+12) / 24
+		next.StartAt = next.DueAt.AddDate(0, 0, -days)
+	}
+	return next
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceRoundTrip(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", "FREQ=DAILY;INTERVAL=2"},
+		{"FREQ=DAILY", "FREQ=DAILY;INTERVAL=1"},
+		{"freq=weekly;byday=mo,we", "FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,15", "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,15"},
+		{"FREQ=AFTER;INTERVAL=10", "FREQ=AFTER;INTERVAL=10"},
+		{"FREQ=LUNARMONTHLY;INTERVAL=1;BYMONTHDAY=1,15", "FREQ=LUNARMONTHLY;INTERVAL=1;BYMONTHDAY=1,15"},
+		{"FREQ=LUNARYEARLY;INTERVAL=1;BYMONTHDAY=10;BYMONTH=3", "FREQ=LUNARYEARLY;INTERVAL=1;BYMONTHDAY=10;BYMONTH=3"},
+	}
+
+	for _, test := range tests {
+		rule, err := ParseRecurrence(test.in)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", test.in, err)
+			continue
+		}
+		if got := rule.String(); got != test.want {
+			t.Errorf("ParseRecurrence(%q).String() = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=DAILY;INTERVAL=-1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=a",
+	} {
+		if _, err := ParseRecurrence(in); err == nil {
+			t.Errorf("ParseRecurrence(%q) succeeded", in)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		rule      string
+		due, done time.Time
+		want      time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=2", day(2024, 3, 4), day(2024, 3, 4), day(2024, 3, 6)},
+		{"FREQ=DAILY", day(2024, 3, 1), day(2024, 3, 10), day(2024, 3, 11)},
+		{"FREQ=DAILY", time.Time{}, day(2024, 3, 10), day(2024, 3, 11)},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", day(2024, 3, 4), day(2024, 3, 4), day(2024, 3, 6)},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", day(2024, 3, 6), day(2024, 3, 6), day(2024, 3, 11)},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", day(2024, 1, 15), day(2024, 1, 20), day(2024, 2, 15)},
+		{"FREQ=AFTER;INTERVAL=3", day(2024, 3, 1), day(2024, 3, 10), day(2024, 3, 13)},
+		// Tết Giáp Thìn, then the first day of the second lunar month
+		{"FREQ=LUNARMONTHLY;BYMONTHDAY=1", day(2024, 2, 10), day(2024, 2, 10), day(2024, 3, 10)},
+	}
+
+	for _, test := range tests {
+		rule, err := ParseRecurrence(test.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rule.Next(test.due, test.done); !got.Equal(test.want) {
+			t.Errorf("%s Next(%v, %v) = %v, want %v", test.rule, test.due.Format(time.DateOnly),
+				test.done.Format(time.DateOnly), got.Format(time.DateOnly), test.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestMarkCompleteRepeat(t *testing.T) {
+	dir := t.TempDir()
+	list := NewTodoList(NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project")), "")
+	rule, _ := ParseRecurrence("FREQ=DAILY")
+	todo, err := list.AddTodoItem(Todo{Description: "tưới cây", Recurrence: rule, DueAt: startOfDay(time.Now())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, repeated, err := list.MarkCompleteRepeat(todo.ID)
+	if err != nil || !repeated {
+		t.Fatalf("MarkCompleteRepeat = %v, %v", repeated, err)
+	}
+	if next.Recurrence.String() != rule.String() || !next.DueAt.After(todo.DueAt) {
+		t.Errorf("next occurrence = %+v", next)
+	}
+	done, _ := list.GetTodo(todo.ID)
+	if !done.Completed || !done.Recurrence.IsZero() {
+		t.Errorf("completed todo = %+v, want completed without rule", done)
+	}
+
+	// The rule and the next occurrence survive a reload
+	reloaded := NewTodoList(list.store, "")
+	if got, ok := reloaded.GetTodo(next.ID); !ok || got.Recurrence.String() != rule.String() {
+		t.Errorf("reloaded next occurrence = %+v, %v", got, ok)
+	}
+}
+
+func TestCompleteWithChildrenSpawned(t *testing.T) {
+	dir := t.TempDir()
+	list := NewTodoList(NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project")), "")
+	rule, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO")
+	parent, _ := list.AddTodoItem(Todo{Description: "dọn nhà"})
+	child, _ := list.AddTodoItem(Todo{Description: "lau bếp", ParentID: parent.ID, Recurrence: rule})
+	list.AddTodoItem(Todo{Description: "giặt rèm", ParentID: parent.ID})
+
+	spawned, err := list.CompleteWithChildren(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spawned) != 1 {
+		t.Fatalf("spawned = %v, want one next occurrence", spawned)
+	}
+	next, ok := list.GetTodo(spawned[0])
+	if !ok || next.Description != child.Description || next.Completed || next.ParentID != parent.ID {
+		t.Errorf("next occurrence = %+v, %v", next, ok)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SearchResult is a todo found by a search, with the project it belongs to
+type SearchResult struct {
+	Project string // "" for the main list
+	Todo    Todo
+}
+
+// searchHelp explains the query language in the search help dialog
+const searchHelp = `Các điều kiện cách nhau bởi dấu cách, công việc phải thỏa tất cả:
+
+  sửa xe              mô tả chứa các từ (không phân biệt hoa thường, dấu)
+  "xe đạp"            mô tả chứa cả cụm từ
+  is:open / is:done   chưa xong / đã hoàn thành
+  is:overdue, is:today, is:upcoming, is:repeat, is:subtask
+  created:>2025-10-01 ngày tạo (so sánh >, >=, <, <=, =)
+  due:<=today         hạn chót (today, tomorrow, yesterday, 2025-10-01, 01/10/2025)
+  start:, completed:  ngày bắt đầu, ngày hoàn thành; due:none / due:any
+  #nhà hoặc tag:nhà   có thẻ
+  priority:>=high     độ ưu tiên (none, low, medium, high, urgent)
+  project:wee         tên project chứa "wee"; project:none là danh sách chính
+  -is:done            thêm "-" phía trước để phủ định
+  "lưu ý:abc"         chữ có dấu ":" sau một từ phải đặt trong ngoặc kép
+
+"⭐ Lưu danh sách" lưu truy vấn thành một tab con luôn cập nhật, tìm
+trong danh sách chính lẫn mọi project.`
+
+// createSearchBar creates the search box shown above the tabs
+func (app *TodoApp) createSearchBar() fyne.CanvasObject {
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("🔍 Tìm kiếm, vd. sửa is:open created:>2025-10-01 project:wee")
+	searchEntry.OnSubmitted = func(text string) {
+		app.search(text)
+	}
+
+	searchBtn := widget.NewButton("Tìm", func() {
+		app.search(searchEntry.Text)
+	})
+	helpBtn := widget.NewButton("❓", func() {
+		help := widget.NewLabel(searchHelp)
+		help.TextStyle = fyne.TextStyle{Monospace: true}
+		dialog.ShowCustom("Cú pháp tìm kiếm", "Đóng", help, app.window)
+	})
+
+	saveBtn := widget.NewButton("⭐ Lưu danh sách", func() {
+		app.showSmartListDialog("", searchEntry.Text)
+	})
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(searchBtn, saveBtn, helpBtn), searchEntry)
+}
+
+// search runs a query over the main list and every project and shows the
+// results
+func (app *TodoApp) search(text string) {
+	query, err := ParseQuery(text)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("truy vấn không hợp lệ: %v", err), app.window)
+		return
+	}
+	if query.IsZero() {
+		return
+	}
+
+	results, err := app.searchAll(query, SortCreated)
+	if err != nil {
+		dialog.ShowError(err, app.window)
+		return
+	}
+	app.showSearchResults(text, results)
+}
+
+// searchAll returns the todos of the main list and of every project that
+// match a query, sorted by a sort mode within each list
+func (app *TodoApp) searchAll(query Query, sortMode string) ([]SearchResult, error) {
+	projects, err := app.store.ListProjects()
+	if err != nil {
+		return nil, fmt.Errorf("không thể đọc danh sách project: %v", err)
+	}
+
+	keys := []string{""}
+	for _, project := range projects {
+		keys = append(keys, project.Name)
+	}
+
+	var results []SearchResult
+	for _, key := range keys {
+		todos := query.Filter(app.listFor(key).GetTodos(), key)
+		sortTodos(todos, sortMode)
+		for _, todo := range todos {
+			results = append(results, SearchResult{Project: key, Todo: todo})
+		}
+	}
+	return results, nil
+}
+
+// searchResultText returns the line shown for a search result
+func searchResultText(result SearchResult) string {
+	status := "⬜"
+	if result.Todo.Completed {
+		status = "✅"
+	}
+	place := "📋 Todos"
+	if result.Project != "" {
+		place = "📁 " + result.Project
+	}
+	text := fmt.Sprintf("%s %s • %s", status, result.Todo.Description, place)
+	if !result.Todo.DueAt.IsZero() {
+		text += " • 🗓️ " + result.Todo.DueAt.Format("02/01/2006")
+	}
+	return text
+}
+
+// showSearchResults lists the results of a search; choosing one opens it
+func (app *TodoApp) showSearchResults(text string, results []SearchResult) {
+	if len(results) == 0 {
+		dialog.ShowInformation("🔍 Tìm kiếm", fmt.Sprintf("Không tìm thấy công việc nào cho: %s", text), app.window)
+		return
+	}
+
+	var d dialog.Dialog
+	list := widget.NewList(
+		func() int {
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(searchResultText(results[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		d.Hide()
+		app.openSearchResult(results[id])
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("Tìm thấy %d công việc cho: %s", len(results), text)),
+		nil, nil, nil,
+		list,
+	)
+	d = dialog.NewCustom("🔍 Kết quả tìm kiếm", "Đóng", content, app.window)
+	d.Resize(fyne.NewSize(700, 500))
+	d.Show()
+}
+
+// openSearchResult switches to the list holding a found todo, makes it
+// visible in "Tất cả" and shows its actions
+func (app *TodoApp) openSearchResult(result SearchResult) {
+	isProject := result.Project != ""
+	if isProject {
+		if app.currentProject != result.Project {
+			app.projectSelect.SetSelected(result.Project)
+		}
+		if app.currentProject != result.Project {
+			app.loadProject(result.Project)
+		}
+		app.tabs.SelectIndex(1)
+	} else {
+		app.tabs.SelectIndex(0)
+	}
+
+	// Clear the tag filter and expand the parents of the todo
+	tab := app.listTab(isProject)
+	tab.tagFilter = ""
+	list := app.listFor(result.Project)
+	seen := make(map[int]bool)
+	for id := result.Todo.ParentID; id != 0 && !seen[id]; {
+		seen[id] = true
+		delete(tab.collapsed, id)
+		parent, ok := list.GetTodo(id)
+		if !ok {
+			break
+		}
+		id = parent.ParentID
+	}
+	app.refreshAllLists()
+
+	if tab.subTabs != nil {
+		tab.subTabs.SelectIndex(0)
+	}
+	view := tab.views["all"]
+	if row := view.indexOf(result.Todo.ID); row >= 0 {
+		view.list.UnselectAll()
+		view.list.ScrollTo(row)
+		view.list.Select(row)
+	}
+}
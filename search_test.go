@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSearchAll(t *testing.T) {
+	dir := t.TempDir()
+	store := NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project"))
+	app := &TodoApp{store: store, todoList: NewTodoList(store, "")}
+
+	if err := app.todoList.AddTodo("Việc chính"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"wee", "khác"} {
+		if err := store.CreateProject(ProjectMeta{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		list := NewTodoList(store, name)
+		for _, description := range []string{"Việc " + name, "Xong " + name} {
+			if err := list.AddTodo(description); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := list.MarkComplete(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Results as "project/description"; projects come in order of change
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// The example of a smart list, while the main list is the open one
+		{"is:open project:wee", []string{"wee/Việc wee"}},
+		{"is:done", []string{"khác/Xong khác", "wee/Xong wee"}},
+		{"việc -project:none", []string{"khác/Việc khác", "wee/Việc wee"}},
+		{"chính", []string{"/Việc chính"}},
+	}
+
+	for _, test := range tests {
+		query, err := ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := app.searchAll(query, SortCreated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Project+"/"+result.Todo.Description)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("searchAll(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// smartListPrefix starts the list type of a smart list, e.g. "smart:Gấp"
+const smartListPrefix = "smart:"
+
+// smartListType returns the list type of the smart list with a name
+func smartListType(name string) string {
+	return smartListPrefix + name
+}
+
+// smartListName returns the name of a smart list from its list type
+func smartListName(listType string) (string, bool) {
+	return strings.CutPrefix(listType, smartListPrefix)
+}
+
+// listTypes returns the sub-tabs of the lists in display order: the
+// built-in lists, the smart lists of the config, then the trash
+func (app *TodoApp) listTypes() []string {
+	types := make([]string, 0, len(listTypes)+len(app.config.SmartLists))
+	for _, listType := range listTypes {
+		if listType == "trash" {
+			for _, smart := range app.config.SmartLists {
+				types = append(types, smartListType(smart.Name))
+			}
+		}
+		types = append(types, listType)
+	}
+	return types
+}
+
+// setSmartListQuery parses the query of a smart list into its view
+func (app *TodoApp) setSmartListQuery(view *todoView, name string) {
+	view.filter, view.filterErr = nil, nil
+	for _, smart := range app.config.SmartLists {
+		if smart.Name != name {
+			continue
+		}
+		query, err := ParseQuery(smart.Query)
+		if err != nil {
+			view.filterErr = err
+			return
+		}
+		view.filter = &query
+		return
+	}
+}
+
+// createSmartResultList creates the rows of a smart list. Like search
+// results, each row shows the list of its todo, and choosing it opens the
+// todo in that list.
+func (app *TodoApp) createSmartResultList(view *todoView) *widget.List {
+	list := widget.NewList(
+		func() int {
+			return len(view.results)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(searchResultText(view.results[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		result := view.results[id]
+		list.UnselectAll()
+		app.openSearchResult(result)
+	}
+	return list
+}
+
+// refreshSmartList runs the query of a smart list over the main list and
+// every project, keeping the todos with the tag of the tag filter
+func (app *TodoApp) refreshSmartList(view *todoView, tagFilter string) {
+	view.results = nil
+	if view.filter != nil {
+		results, err := app.searchAll(*view.filter, view.sortMode)
+		if err != nil {
+			fmt.Printf("❌ Error refreshing smart list: %v\n", err)
+		}
+		for _, result := range results {
+			if tagFilter == "" || result.Todo.HasTag(tagFilter) {
+				view.results = append(view.results, result)
+			}
+		}
+	}
+	view.resultList.Refresh()
+}
+
+// smartListQuery returns the saved query of a smart list
+func (app *TodoApp) smartListQuery(name string) string {
+	for _, smart := range app.config.SmartLists {
+		if smart.Name == name {
+			return smart.Query
+		}
+	}
+	return ""
+}
+
+// createSmartListHeader creates the sort selector, the query and the edit
+// and delete buttons shown above a smart list
+func (app *TodoApp) createSmartListHeader(view *todoView, listType string) fyne.CanvasObject {
+	name, _ := smartListName(listType)
+
+	queryLabel := widget.NewLabel("🔎 " + app.smartListQuery(name))
+	queryLabel.TextStyle = fyne.TextStyle{Italic: true}
+	if view.filterErr != nil {
+		queryLabel.SetText(fmt.Sprintf("⚠️ Truy vấn không hợp lệ: %v", view.filterErr))
+		queryLabel.Importance = widget.DangerImportance
+	}
+
+	editBtn := widget.NewButton("✏️ Sửa", func() {
+		app.showSmartListDialog(name, app.smartListQuery(name))
+	})
+	deleteBtn := widget.NewButton("🗑️ Xóa danh sách", func() {
+		app.confirmDeleteSmartList(name)
+	})
+	deleteBtn.Importance = widget.DangerImportance
+
+	return container.NewVBox(
+		container.NewHBox(app.createSortSelector(view), editBtn, deleteBtn),
+		queryLabel,
+	)
+}
+
+// showSmartListDialog creates a smart list from a query, or edits the
+// smart list named old when old is not ""
+func (app *TodoApp) showSmartListDialog(old, query string) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("vd. Việc gấp tuần này")
+	nameEntry.SetText(old)
+
+	queryEntry := widget.NewEntry()
+	queryEntry.SetPlaceHolder("vd. is:open priority:>=high due:<=tomorrow")
+	queryEntry.SetText(query)
+
+	title := "⭐ Lưu danh sách thông minh"
+	if old != "" {
+		title = "✏️ Sửa danh sách thông minh"
+	}
+
+	form := []*widget.FormItem{
+		widget.NewFormItem("Tên", nameEntry),
+		widget.NewFormItem("Truy vấn", queryEntry),
+	}
+	d := dialog.NewForm(title, "Lưu", "Hủy", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		smart := SmartList{Name: nameEntry.Text, Query: queryEntry.Text}
+		if err := app.config.SetSmartList(old, smart); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		if err := app.config.Save(); err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+
+		app.syncSmartLists()
+		app.selectSubTab(smartListType(strings.TrimSpace(smart.Name)))
+		app.snackbar.Show(fmt.Sprintf("Đã lưu danh sách: %s", strings.TrimSpace(smart.Name)), "", nil)
+	}, app.window)
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
+
+// confirmDeleteSmartList asks before deleting a smart list; the todos it
+// shows are not changed
+func (app *TodoApp) confirmDeleteSmartList(name string) {
+	dialog.ShowConfirm("Xóa danh sách thông minh",
+		fmt.Sprintf("Xóa danh sách %q?\nCác công việc trong danh sách không bị xóa.", name),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			app.config.RemoveSmartList(name)
+			if err := app.config.Save(); err != nil {
+				dialog.ShowError(err, app.window)
+				return
+			}
+			app.syncSmartLists()
+		}, app.window)
+}
+
+// syncSmartLists updates the smart list views and sub-tabs of both tabs
+// after the smart lists of the config changed
+func (app *TodoApp) syncSmartLists() {
+	for _, isProject := range []bool{false, true} {
+		tab := app.todoTab
+		if isProject {
+			tab = app.projectTab
+		}
+		if tab == nil {
+			continue
+		}
+
+		types := app.listTypes()
+		wanted := make(map[string]bool, len(types))
+		for _, listType := range types {
+			wanted[listType] = true
+		}
+		for listType := range tab.views {
+			if !wanted[listType] {
+				delete(tab.views, listType)
+			}
+		}
+
+		var items []*container.TabItem
+		for _, listType := range types {
+			view := tab.views[listType]
+			if view == nil {
+				view = app.newView(listType, isProject)
+				tab.views[listType] = view
+			}
+			if name, ok := smartListName(listType); ok || view.tabItem == nil {
+				if ok {
+					app.setSmartListQuery(view, name)
+				}
+				app.createSubTab(view, listType, isProject)
+			}
+			items = append(items, view.tabItem)
+		}
+
+		if tab.subTabs != nil {
+			selected := tab.subTabs.Selected()
+			tab.subTabs.Items = items
+			tab.subTabs.Refresh()
+			if selected != nil {
+				for _, item := range items {
+					if item.Text == selected.Text {
+						tab.subTabs.Select(item)
+					}
+				}
+			}
+		}
+	}
+	app.refreshAllLists()
+}
+
+// selectSubTab shows the sub-tab of a list type in the current tab
+func (app *TodoApp) selectSubTab(listType string) {
+	if app.tabs == nil {
+		return
+	}
+	tab := app.listTab(app.tabs.SelectedIndex() == 1)
+	if view := tab.views[listType]; view != nil && view.tabItem != nil && tab.subTabs != nil {
+		tab.subTabs.Select(view.tabItem)
+	}
+}
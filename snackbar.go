@@ -0,0 +1,77 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// snackbarDuration is how long a snackbar stays on screen
+const snackbarDuration = 6 * time.Second
+
+// Snackbar shows a short message at the bottom of the window with an
+// optional action button, e.g. "Hoàn tác" after a destructive action
+type Snackbar struct {
+	window fyne.Window
+	popup  *widget.PopUp
+	timer  *time.Timer
+}
+
+// NewSnackbar creates a snackbar for the given window
+func NewSnackbar(window fyne.Window) *Snackbar {
+	return &Snackbar{window: window}
+}
+
+// Show displays a message, replacing any snackbar already on screen.
+// If action is non-nil a button with actionLabel is shown next to it.
+func (s *Snackbar) Show(message, actionLabel string, action func()) {
+	s.Hide()
+
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(nil, nil, nil, nil, label)
+	if action != nil {
+		actionBtn := widget.NewButton(actionLabel, func() {
+			s.Hide()
+			action()
+		})
+		actionBtn.Importance = widget.HighImportance
+		content = container.NewBorder(nil, nil, nil, actionBtn, label)
+	}
+
+	canvas := s.window.Canvas()
+	popup := widget.NewPopUp(container.NewPadded(content), canvas)
+
+	// Bottom center of the window
+	canvasSize := canvas.Size()
+	width := canvasSize.Width * 0.6
+	popup.Resize(fyne.NewSize(width, popup.MinSize().Height))
+	popup.ShowAtPosition(fyne.NewPos(
+		(canvasSize.Width-width)/2,
+		canvasSize.Height-popup.MinSize().Height-20,
+	))
+	s.popup = popup
+
+	s.timer = time.AfterFunc(snackbarDuration, func() {
+		fyne.Do(func() {
+			if s.popup == popup {
+				s.Hide()
+			}
+		})
+	})
+}
+
+// Hide removes the snackbar from the screen
+func (s *Snackbar) Hide() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.popup != nil {
+		s.popup.Hide()
+		s.popup = nil
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Storage backend names used in the config file
+const (
+	BackendText     = "text"
+	BackendJSON     = "json"
+	BackendEmbedded = "embedded"
+)
+
+// ProjectMeta holds the metadata stored alongside a project's todos
+type ProjectMeta struct {
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	Color           string    `json:"color"`
+	BackgroundImage string    `json:"background_image,omitempty"`
+	Created         time.Time `json:"created"`
+}
+
+// ProjectInfo describes a project as returned by Store.ListProjects
+type ProjectInfo struct {
+	Name    string
+	ModTime time.Time
+}
+
+// Store persists todo lists and projects.
+// The project argument selects a list: "" is the main todo list, any other
+// value is the name of a project.
+type Store interface {
+	// LoadTodos returns the todos of a list; a missing list is empty
+	LoadTodos(project string) ([]Todo, error)
+	// SaveTodos replaces the todos of a list, keeping its metadata
+	SaveTodos(project string, todos []Todo) error
+
+	// ListProjects returns all projects, most recently modified first
+	ListProjects() ([]ProjectInfo, error)
+	// LoadProject returns the metadata of a project
+	LoadProject(name string) (ProjectMeta, error)
+	// CreateProject creates an empty project; it fails if it already exists
+	CreateProject(meta ProjectMeta) error
+	// UpdateProject replaces the metadata of an existing project
+	UpdateProject(meta ProjectMeta) error
+	// DeleteProject removes a project and its todos
+	DeleteProject(name string) error
+
+	// ListBackups returns the backups available for a list, newest first
+	ListBackups(project string) []BackupInfo
+	// LoadBackup returns the todos of a list as stored in a backup
+	LoadBackup(project, path string) ([]Todo, error)
+
+	// JournalPath returns the file holding the operation journal of a list
+	JournalPath(project string) string
+	// DataPath returns the file holding the todos of a list
+	DataPath(project string) string
+	// WatchDirs returns the directories holding the data files, watched for
+	// changes made by other programs
+	WatchDirs() []string
+}
+
+// NewStore creates the storage backend with the given name, keeping its
+// files in the data root dataDir
+func NewStore(backend, dataDir string) (Store, error) {
+	switch backend {
+	case BackendText, "":
+		return NewTextStore(filepath.Join(dataDir, "todos.txt"), filepath.Join(dataDir, "project")), nil
+	case BackendJSON:
+		return NewJSONStore(filepath.Join(dataDir, "json")), nil
+	case BackendEmbedded:
+		return NewEmbeddedStore(filepath.Join(dataDir, "todoapp.db")), nil
+	default:
+		return nil, fmt.Errorf("backend lưu trữ không hợp lệ: %s", backend)
+	}
+}
+
+// validateProjectName rejects names that can't be used as a project key
+func validateProjectName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("tên project không được để trống")
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("tên project không hợp lệ: %s", name)
+	}
+	return nil
+}
+
+// CopyStore copies the main list and every project from one store into
+// another. Projects that already exist in the destination are overwritten.
+// Lists are read through open, which returns the TodoList of a list in the
+// source store, so that pending journal entries are included and lists
+// already open are reused rather than opened twice.
+func CopyStore(from, to Store, open func(project string) *TodoList) error {
+	if err := copyList(open(""), to, ""); err != nil {
+		return err
+	}
+
+	projects, err := from.ListProjects()
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		meta, err := from.LoadProject(project.Name)
+		if err != nil {
+			return err
+		}
+		if err := to.CreateProject(meta); err != nil {
+			if err := to.UpdateProject(meta); err != nil {
+				return err
+			}
+		}
+
+		if err := copyList(open(project.Name), to, project.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyList copies one list, trash included, into a store. Pending journal
+// entries left in the destination are archived so they aren't replayed over
+// the copy.
+func copyList(list *TodoList, to Store, project string) error {
+	if err := to.SaveTodos(project, list.AllTodos()); err != nil {
+		return err
+	}
+	return OpenJournal(to.JournalPath(project)).Compact()
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// embeddedProject is one project inside the embedded database
+type embeddedProject struct {
+	Meta     ProjectMeta `json:"meta"`
+	Todos    []Todo      `json:"todos"`
+	Modified time.Time   `json:"modified"`
+}
+
+// embeddedDB is the whole content of the embedded database file
+type embeddedDB struct {
+	Format   int                         `json:"format"`
+	Todos    []Todo                      `json:"todos"`
+	Modified time.Time                   `json:"modified"`
+	Projects map[string]*embeddedProject `json:"projects"`
+}
+
+// EmbeddedStore keeps every list and project in a single database file.
+// Each change rewrites the file atomically, so the file is always consistent
+// and can be copied or backed up as a unit.
+type EmbeddedStore struct {
+	mu          sync.Mutex
+	filename    string
+	backupCount int
+}
+
+// NewEmbeddedStore creates an embedded single-file backend
+func NewEmbeddedStore(filename string) *EmbeddedStore {
+	return &EmbeddedStore{
+		filename:    filename,
+		backupCount: defaultBackupCount,
+	}
+}
+
+// readDB decodes a database file; a missing file is an empty database
+func (s *EmbeddedStore) readDB(path string) (*embeddedDB, error) {
+	db := &embeddedDB{Projects: map[string]*embeddedProject{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return db, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, fmt.Errorf("file dữ liệu không hợp lệ %s: %v", path, err)
+	}
+	if db.Projects == nil {
+		db.Projects = map[string]*embeddedProject{}
+	}
+	return db, nil
+}
+
+// update runs fn on the current database and writes the result back. The
+// database file stays locked in between so that another process cannot
+// write it at the same time.
+func (s *EmbeddedStore) update(fn func(db *embeddedDB) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withFileLock(s.filename, func() error {
+		db, err := s.readDB(s.filename)
+		if err != nil {
+			return err
+		}
+		if err := fn(db); err != nil {
+			return err
+		}
+
+		db.Format = jsonFormatVersion
+		return writeFileAtomic(s.filename, s.backupCount, func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(db)
+		})
+	})
+}
+
+// view runs fn on the current database without modifying it
+func (s *EmbeddedStore) view(fn func(db *embeddedDB) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := s.readDB(s.filename)
+	if err != nil {
+		return err
+	}
+	return fn(db)
+}
+
+// project returns a project from the database or an error if it's missing
+func (db *embeddedDB) project(name string) (*embeddedProject, error) {
+	project, ok := db.Projects[name]
+	if !ok {
+		return nil, fmt.Errorf("không tìm thấy project %s", name)
+	}
+	return project, nil
+}
+
+// LoadTodos loads the todos of a list
+func (s *EmbeddedStore) LoadTodos(project string) ([]Todo, error) {
+	var todos []Todo
+	err := s.view(func(db *embeddedDB) error {
+		if project == "" {
+			todos = db.Todos
+			return nil
+		}
+		if p, ok := db.Projects[project]; ok {
+			todos = p.Todos
+		}
+		return nil
+	})
+	return todos, err
+}
+
+// SaveTodos replaces the todos of a list
+func (s *EmbeddedStore) SaveTodos(project string, todos []Todo) error {
+	return s.update(func(db *embeddedDB) error {
+		now := time.Now()
+		if project == "" {
+			db.Todos = todos
+			db.Modified = now
+			return nil
+		}
+
+		p, err := db.project(project)
+		if err != nil {
+			return err
+		}
+		p.Todos = todos
+		p.Modified = now
+		return nil
+	})
+}
+
+// ListProjects lists the projects, most recently modified first
+func (s *EmbeddedStore) ListProjects() ([]ProjectInfo, error) {
+	var projects []ProjectInfo
+	err := s.view(func(db *embeddedDB) error {
+		for name, p := range db.Projects {
+			projects = append(projects, ProjectInfo{Name: name, ModTime: p.Modified})
+		}
+		return nil
+	})
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ModTime.After(projects[j].ModTime)
+	})
+	return projects, err
+}
+
+// LoadProject returns the metadata of a project
+func (s *EmbeddedStore) LoadProject(name string) (ProjectMeta, error) {
+	var meta ProjectMeta
+	err := s.view(func(db *embeddedDB) error {
+		p, err := db.project(name)
+		if err != nil {
+			return err
+		}
+		meta = p.Meta
+		return nil
+	})
+	return meta, err
+}
+
+// CreateProject adds an empty project to the database
+func (s *EmbeddedStore) CreateProject(meta ProjectMeta) error {
+	if err := validateProjectName(meta.Name); err != nil {
+		return err
+	}
+
+	return s.update(func(db *embeddedDB) error {
+		if _, ok := db.Projects[meta.Name]; ok {
+			return fmt.Errorf("project %s đã tồn tại", meta.Name)
+		}
+		db.Projects[meta.Name] = &embeddedProject{
+			Meta:     meta,
+			Todos:    []Todo{},
+			Modified: time.Now(),
+		}
+		return nil
+	})
+}
+
+// UpdateProject replaces the metadata of a project
+func (s *EmbeddedStore) UpdateProject(meta ProjectMeta) error {
+	return s.update(func(db *embeddedDB) error {
+		p, err := db.project(meta.Name)
+		if err != nil {
+			return err
+		}
+		p.Meta = meta
+		p.Modified = time.Now()
+		return nil
+	})
+}
+
+// DeleteProject removes a project and its todos from the database
+func (s *EmbeddedStore) DeleteProject(name string) error {
+	err := s.update(func(db *embeddedDB) error {
+		if _, err := db.project(name); err != nil {
+			return err
+		}
+		delete(db.Projects, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return OpenJournal(s.JournalPath(name)).Remove()
+}
+
+// JournalPath returns the journal of a list. Journals live in a directory
+// next to the database file, since the database itself is rewritten as a
+// whole.
+func (s *EmbeddedStore) JournalPath(project string) string {
+	dir := s.filename + ".journal"
+	if project == "" {
+		return filepath.Join(dir, "todos.journal")
+	}
+	return filepath.Join(dir, "project", project+".journal")
+}
+
+// DataPath returns the database file, which holds every list
+func (s *EmbeddedStore) DataPath(string) string {
+	return s.filename
+}
+
+// WatchDirs returns the directory of the database file and the journal
+// directories
+func (s *EmbeddedStore) WatchDirs() []string {
+	dir := s.filename + ".journal"
+	return []string{filepath.Dir(s.filename), dir, filepath.Join(dir, "project")}
+}
+
+// ListBackups lists the backups of the database file. Every backup holds
+// all lists; the todo count reported is the one of the requested list.
+func (s *EmbeddedStore) ListBackups(project string) []BackupInfo {
+	return listBackupFiles(s.filename, s.backupCount, func(path string) int {
+		todos, err := s.LoadBackup(project, path)
+		if err != nil {
+			return 0
+		}
+		return len(todos)
+	})
+}
+
+// LoadBackup reads the todos of one list from a backup of the database
+func (s *EmbeddedStore) LoadBackup(project, path string) ([]Todo, error) {
+	db, err := s.readDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if project == "" {
+		return db.Todos, nil
+	}
+
+	p, err := db.project(project)
+	if err != nil {
+		return nil, err
+	}
+	return p.Todos, nil
+}
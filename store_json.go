@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// jsonFormatVersion is the version written into every JSON document
+const jsonFormatVersion = 1
+
+// jsonListFile is the document stored for one list by the JSON backend
+type jsonListFile struct {
+	Format  int          `json:"format"`
+	Project *ProjectMeta `json:"project,omitempty"`
+	Todos   []Todo       `json:"todos"`
+}
+
+// JSONStore stores each list as a JSON document: <dir>/todos.json for the
+// main list and <dir>/project/<name>.json for projects, so that other tools
+// can read the data directly.
+type JSONStore struct {
+	dir         string
+	backupCount int
+}
+
+// NewJSONStore creates a JSON backend rooted at dir
+func NewJSONStore(dir string) *JSONStore {
+	return &JSONStore{
+		dir:         dir,
+		backupCount: defaultBackupCount,
+	}
+}
+
+// path returns the document that holds a list
+func (s *JSONStore) path(project string) string {
+	if project == "" {
+		return filepath.Join(s.dir, "todos.json")
+	}
+	return filepath.Join(s.dir, "project", project+".json")
+}
+
+// readFile decodes a list document
+func (s *JSONStore) readFile(path string) (*jsonListFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc jsonListFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("file JSON không hợp lệ %s: %v", path, err)
+	}
+	return &doc, nil
+}
+
+// writeFile atomically encodes a list document, rotating backups
+func (s *JSONStore) writeFile(path string, doc *jsonListFile, keepBackups int) error {
+	doc.Format = jsonFormatVersion
+	if doc.Todos == nil {
+		doc.Todos = []Todo{}
+	}
+
+	return writeFileAtomic(path, keepBackups, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	})
+}
+
+// LoadTodos loads the todos of a list
+func (s *JSONStore) LoadTodos(project string) ([]Todo, error) {
+	doc, err := s.readFile(s.path(project))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return doc.Todos, nil
+}
+
+// SaveTodos writes the todos of a list while keeping its project metadata
+func (s *JSONStore) SaveTodos(project string, todos []Todo) error {
+	path := s.path(project)
+
+	return withFileLock(path, func() error {
+		doc, err := s.readFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			doc = &jsonListFile{}
+		}
+
+		doc.Todos = todos
+		return s.writeFile(path, doc, s.backupCount)
+	})
+}
+
+// ListProjects lists the project documents, most recently modified first
+func (s *JSONStore) ListProjects() ([]ProjectInfo, error) {
+	files, err := os.ReadDir(filepath.Join(s.dir, "project"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var projects []ProjectInfo
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		projects = append(projects, ProjectInfo{
+			Name:    strings.TrimSuffix(file.Name(), ".json"),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ModTime.After(projects[j].ModTime)
+	})
+	return projects, nil
+}
+
+// LoadProject returns the metadata of a project
+func (s *JSONStore) LoadProject(name string) (ProjectMeta, error) {
+	doc, err := s.readFile(s.path(name))
+	if err != nil {
+		return ProjectMeta{}, err
+	}
+	if doc.Project == nil {
+		return ProjectMeta{Name: name, Color: "blue"}, nil
+	}
+
+	meta := *doc.Project
+	meta.Name = name
+	return meta, nil
+}
+
+// CreateProject creates an empty project document
+func (s *JSONStore) CreateProject(meta ProjectMeta) error {
+	if err := validateProjectName(meta.Name); err != nil {
+		return err
+	}
+
+	path := s.path(meta.Name)
+	return withFileLock(path, func() error {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("project %s đã tồn tại", meta.Name)
+		}
+		return s.writeFile(path, &jsonListFile{Project: &meta}, 0)
+	})
+}
+
+// UpdateProject replaces the metadata of a project document
+func (s *JSONStore) UpdateProject(meta ProjectMeta) error {
+	path := s.path(meta.Name)
+	return withFileLock(path, func() error {
+		doc, err := s.readFile(path)
+		if err != nil {
+			return err
+		}
+
+		doc.Project = &meta
+		return s.writeFile(path, doc, s.backupCount)
+	})
+}
+
+// DeleteProject removes a project document and its journal. Its backups
+// are left in place.
+func (s *JSONStore) DeleteProject(name string) error {
+	if err := os.Remove(s.path(name)); err != nil {
+		return err
+	}
+	return OpenJournal(s.JournalPath(name)).Remove()
+}
+
+// JournalPath returns the journal file stored next to a list document
+func (s *JSONStore) JournalPath(project string) string {
+	return s.path(project) + ".journal"
+}
+
+// DataPath returns the document holding a list
+func (s *JSONStore) DataPath(project string) string {
+	return s.path(project)
+}
+
+// WatchDirs returns the directories of the main and project documents
+func (s *JSONStore) WatchDirs() []string {
+	return []string{s.dir, filepath.Join(s.dir, "project")}
+}
+
+// ListBackups lists the .bak generations of a list document
+func (s *JSONStore) ListBackups(project string) []BackupInfo {
+	return listBackupFiles(s.path(project), s.backupCount, func(path string) int {
+		doc, err := s.readFile(path)
+		if err != nil {
+			return 0
+		}
+		return len(doc.Todos)
+	})
+}
+
+// LoadBackup reads the todos stored in a backup document
+func (s *JSONStore) LoadBackup(project, path string) ([]Todo, error) {
+	doc, err := s.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Todos, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyStore(t *testing.T) {
+	dir := t.TempDir()
+	from := NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project"))
+	to := NewJSONStore(filepath.Join(dir, "json"))
+	if err := from.CreateProject(ProjectMeta{Name: "Việc nhà", Color: "green", Created: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	open := map[string]*TodoList{"": NewTodoList(from, ""), "Việc nhà": NewTodoList(from, "Việc nhà")}
+	open[""].AddTodo("một")
+	trashed, _ := open[""].AddTodoItem(Todo{Description: "hai"})
+	open[""].DeleteTodo(trashed.ID)
+	open["Việc nhà"].AddTodo("quét nhà")
+
+	opened := 0
+	err := CopyStore(from, to, func(project string) *TodoList {
+		opened++
+		return open[project]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != 2 {
+		t.Errorf("opened %d lists, want 2", opened)
+	}
+
+	if todos, err := to.LoadTodos(""); err != nil || len(todos) != 2 || !todos[1].InTrash() {
+		t.Errorf("copied main list = %+v, %v", todos, err)
+	}
+	if todos, err := to.LoadTodos("Việc nhà"); err != nil || len(todos) != 1 {
+		t.Errorf("copied project = %+v, %v", todos, err)
+	}
+	if meta, err := to.LoadProject("Việc nhà"); err != nil || meta.Color != "green" {
+		t.Errorf("copied project meta = %+v, %v", meta, err)
+	}
+}
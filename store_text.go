@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Header keys used by project files of the text backend
+const (
+	headerProject    = "# Project: "
+	headerDesc       = "# Description: "
+	headerColor      = "# Color: "
+	headerCreated    = "# Created: "
+	headerBackground = "# BackgroundImage: "
+
+	headerCreatedLayout = "2006-01-02 15:04:05"
+)
+
+// TextStore is the original pipe-delimited text backend: the main list lives
+// in a single file and each project in <projectDir>/<name>.txt with "# Key:"
+// header metadata.
+type TextStore struct {
+	mainFile    string
+	projectDir  string
+	backupCount int
+}
+
+// NewTextStore creates a text backend
+func NewTextStore(mainFile, projectDir string) *TextStore {
+	return &TextStore{
+		mainFile:    mainFile,
+		projectDir:  projectDir,
+		backupCount: defaultBackupCount,
+	}
+}
+
+// path returns the file that holds a list
+func (s *TextStore) path(project string) string {
+	if project == "" {
+		return s.mainFile
+	}
+	return filepath.Join(s.projectDir, project+".txt")
+}
+
+// readFile reads the header metadata and todos of a list file
+func (s *TextStore) readFile(path string) ([]string, []Todo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, todos := parseTodoFile(lines)
+	return header, todos, nil
+}
+
+// LoadTodos loads the todos of a list from its text file
+func (s *TextStore) LoadTodos(project string) ([]Todo, error) {
+	_, todos, err := s.readFile(s.path(project))
+	if err != nil && os.IsNotExist(err) {
+		// File doesn't exist yet, which is fine for a new todolist
+		return nil, nil
+	}
+	return todos, err
+}
+
+// SaveTodos writes the todos of a list while preserving its header metadata
+func (s *TextStore) SaveTodos(project string, todos []Todo) error {
+	path := s.path(project)
+
+	return withFileLock(path, func() error {
+		header, _, err := s.readFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.writeFile(path, header, todos)
+	})
+}
+
+// writeFile atomically writes a list file, rotating backups
+func (s *TextStore) writeFile(path string, header []string, todos []Todo) error {
+	return writeFileAtomic(path, s.backupCount, func(w io.Writer) error {
+		return writeTodoFile(w, header, todos)
+	})
+}
+
+// ListProjects lists the project files, most recently modified first
+func (s *TextStore) ListProjects() ([]ProjectInfo, error) {
+	files, err := os.ReadDir(s.projectDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var projects []ProjectInfo
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".txt") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		projects = append(projects, ProjectInfo{
+			Name:    strings.TrimSuffix(file.Name(), ".txt"),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ModTime.After(projects[j].ModTime)
+	})
+	return projects, nil
+}
+
+// LoadProject reads the project metadata from the file header, adding a
+// header first to old project files that don't have one
+func (s *TextStore) LoadProject(name string) (ProjectMeta, error) {
+	path := s.path(name)
+	header, todos, err := s.readFile(path)
+	if err != nil {
+		return ProjectMeta{}, err
+	}
+
+	meta := ProjectMeta{Name: name, Color: "blue"}
+	hasHeader := false
+	for _, line := range header {
+		switch {
+		case strings.HasPrefix(line, headerProject):
+			hasHeader = true
+		case strings.HasPrefix(line, headerDesc):
+			meta.Description = strings.TrimSpace(strings.TrimPrefix(line, headerDesc))
+		case strings.HasPrefix(line, headerColor):
+			meta.Color = strings.TrimSpace(strings.TrimPrefix(line, headerColor))
+		case strings.HasPrefix(line, headerBackground):
+			meta.BackgroundImage = strings.TrimSpace(strings.TrimPrefix(line, headerBackground))
+		case strings.HasPrefix(line, headerCreated):
+			meta.Created = parseCreatedHeader(strings.TrimPrefix(line, headerCreated))
+		}
+	}
+
+	// Migrate old project file format if needed
+	if !hasHeader {
+		meta.Created = time.Now()
+		err := withFileLock(path, func() error {
+			return s.writeFile(path, projectHeader(meta), todos)
+		})
+		if err != nil {
+			fmt.Printf("❌ Error migrating project file %s: %v\n", name, err)
+		} else {
+			fmt.Printf("🔄 Migrated old project file: %s\n", name)
+		}
+	}
+
+	return meta, nil
+}
+
+// CreateProject creates a new project file containing only the header
+func (s *TextStore) CreateProject(meta ProjectMeta) error {
+	if err := validateProjectName(meta.Name); err != nil {
+		return err
+	}
+
+	path := s.path(meta.Name)
+	return withFileLock(path, func() error {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("project %s đã tồn tại", meta.Name)
+		}
+		return writeFileAtomic(path, 0, func(w io.Writer) error {
+			return writeTodoFile(w, projectHeader(meta), nil)
+		})
+	})
+}
+
+// UpdateProject rewrites the color and background image header lines of a
+// project file, keeping every other header line untouched
+func (s *TextStore) UpdateProject(meta ProjectMeta) error {
+	path := s.path(meta.Name)
+	return withFileLock(path, func() error {
+		return s.updateHeader(path, meta)
+	})
+}
+
+// updateHeader rewrites the header of a project file; the caller holds the
+// lock of the file
+func (s *TextStore) updateHeader(path string, meta ProjectMeta) error {
+	header, todos, err := s.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	colorUpdated := false
+	backgroundUpdated := false
+
+	var lines []string
+	for _, line := range header {
+		if strings.HasPrefix(line, headerColor) {
+			lines = append(lines, headerColor+meta.Color)
+			colorUpdated = true
+		} else if strings.HasPrefix(line, headerBackground) {
+			// Drop the background image line if it was cleared
+			if meta.BackgroundImage != "" {
+				lines = append(lines, headerBackground+meta.BackgroundImage)
+			}
+			backgroundUpdated = true
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	// Add missing fields if not found
+	if !colorUpdated {
+		lines = insertHeaderAfter(lines, headerProject, headerColor+meta.Color)
+	}
+	if !backgroundUpdated && meta.BackgroundImage != "" {
+		lines = insertHeaderAfter(lines, headerColor, headerBackground+meta.BackgroundImage)
+	}
+
+	return s.writeFile(path, lines, todos)
+}
+
+// DeleteProject removes a project file and its journal. Its backups are
+// left in place.
+func (s *TextStore) DeleteProject(name string) error {
+	if err := os.Remove(s.path(name)); err != nil {
+		return err
+	}
+	return OpenJournal(s.JournalPath(name)).Remove()
+}
+
+// JournalPath returns the journal file stored next to a list file
+func (s *TextStore) JournalPath(project string) string {
+	return s.path(project) + ".journal"
+}
+
+// DataPath returns the file holding a list
+func (s *TextStore) DataPath(project string) string {
+	return s.path(project)
+}
+
+// WatchDirs returns the directory of the main list file and the project
+// directory
+func (s *TextStore) WatchDirs() []string {
+	return []string{filepath.Dir(s.mainFile), s.projectDir}
+}
+
+// ListBackups lists the .bak generations of a list file
+func (s *TextStore) ListBackups(project string) []BackupInfo {
+	return listBackupFiles(s.path(project), s.backupCount, func(path string) int {
+		_, todos, err := s.readFile(path)
+		if err != nil {
+			return 0
+		}
+		return len(todos)
+	})
+}
+
+// LoadBackup reads the todos stored in a backup file
+func (s *TextStore) LoadBackup(project, path string) ([]Todo, error) {
+	_, todos, err := s.readFile(path)
+	return todos, err
+}
+
+// projectHeader builds the header lines of a new project file
+func projectHeader(meta ProjectMeta) []string {
+	header := []string{
+		headerProject + meta.Name,
+		headerColor + meta.Color,
+		headerCreated + meta.Created.Format(headerCreatedLayout),
+	}
+	if meta.Description != "" {
+		header = append(header, headerDesc+meta.Description)
+	}
+	if meta.BackgroundImage != "" {
+		header = append(header, headerBackground+meta.BackgroundImage)
+	}
+	return append(header, "")
+}
+
+// insertHeaderAfter inserts a header line after the first line with the
+// given prefix
+func insertHeaderAfter(lines []string, prefix, newLine string) []string {
+	for i, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			newLines := make([]string, 0, len(lines)+1)
+			newLines = append(newLines, lines[:i+1]...)
+			newLines = append(newLines, newLine)
+			return append(newLines, lines[i+1:]...)
+		}
+	}
+	return lines
+}
+
+// parseCreatedHeader parses the "# Created:" value, which has been written
+// in several layouts over time
+func parseCreatedHeader(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{headerCreatedLayout, "02/01/2006 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
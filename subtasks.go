@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// AddSubtask adds a new todo as a child of parentID
+func (tl *TodoList) AddSubtask(parentID int, description string) (Todo, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.addTodoItem(Todo{Description: description, ParentID: parentID})
+}
+
+// Children returns the direct children of a todo that are not in the trash
+func (tl *TodoList) Children(id int) []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.children(id)
+}
+
+// children is Children with the mutex held
+func (tl *TodoList) children(id int) []Todo {
+	var children []Todo
+	for _, todo := range tl.todos {
+		if todo.ParentID == id && !todo.InTrash() {
+			children = append(children, todo)
+		}
+	}
+	return children
+}
+
+// Descendants returns the children of a todo, their children and so on,
+// parents before their children
+func (tl *TodoList) Descendants(id int) []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.descendants(id, map[int]bool{id: true})
+}
+
+// descendants collects the descendants of a todo, skipping todos already
+// seen so that a corrupted parent cycle cannot recurse forever
+func (tl *TodoList) descendants(id int, seen map[int]bool) []Todo {
+	var descendants []Todo
+	for _, child := range tl.children(id) {
+		if seen[child.ID] {
+			continue
+		}
+		seen[child.ID] = true
+		descendants = append(descendants, child)
+		descendants = append(descendants, tl.descendants(child.ID, seen)...)
+	}
+	return descendants
+}
+
+// Progress returns how many of the descendants of a todo are completed
+func (tl *TodoList) Progress(id int) (done, total int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	for _, todo := range tl.descendants(id, map[int]bool{id: true}) {
+		total++
+		if todo.Completed {
+			done++
+		}
+	}
+	return done, total
+}
+
+// isDescendant reports whether id is ancestorID or one of its descendants
+func (tl *TodoList) isDescendant(id, ancestorID int) bool {
+	seen := make(map[int]bool)
+	for id != 0 && !seen[id] {
+		if id == ancestorID {
+			return true
+		}
+		seen[id] = true
+
+		parentID := 0
+		for _, todo := range tl.todos {
+			if todo.ID == id {
+				parentID = todo.ParentID
+				break
+			}
+		}
+		id = parentID
+	}
+	return false
+}
+
+// SetParent moves a todo under another todo; a parentID of 0 makes it a
+// top-level todo
+func (tl *TodoList) SetParent(id, parentID int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.setParent(id, parentID)
+}
+
+// setParent is SetParent with the mutex held
+func (tl *TodoList) setParent(id, parentID int) error {
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+	if parentID != 0 {
+		if tl.index(parentID) < 0 {
+			return fmt.Errorf("không tìm thấy công việc cha với ID %d", parentID)
+		}
+		if tl.isDescendant(parentID, id) {
+			return fmt.Errorf("không thể chuyển công việc vào chính nó hoặc công việc con của nó")
+		}
+	}
+
+	tl.todos[i].ParentID = parentID
+	todo := tl.todos[i]
+	return tl.record(OpEdit, id, &todo)
+}
+
+// CompleteWithChildren marks a todo and all its incomplete descendants as
+// completed and returns the IDs of the next occurrences created for the
+// repeating ones
+func (tl *TodoList) CompleteWithChildren(id int) ([]int, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.index(id) < 0 {
+		return nil, fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	var spawned []int
+	complete := func(id int) error {
+		next, repeated, err := tl.markCompleteRepeat(id)
+		if repeated {
+			spawned = append(spawned, next.ID)
+		}
+		return err
+	}
+	for _, todo := range tl.descendants(id, map[int]bool{id: true}) {
+		if todo.Completed {
+			continue
+		}
+		if err := complete(todo.ID); err != nil {
+			return spawned, err
+		}
+	}
+	return spawned, complete(id)
+}
+
+// DeleteWithChildren moves a todo and all its descendants to the trash
+func (tl *TodoList) DeleteWithChildren(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.index(id) < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	descendants := tl.descendants(id, map[int]bool{id: true})
+	if err := tl.deleteTodo(id); err != nil {
+		return err
+	}
+	for _, todo := range descendants {
+		if err := tl.deleteTodo(todo.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteKeepChildren moves a todo to the trash and re-parents its direct
+// children to its own parent
+func (tl *TodoList) DeleteKeepChildren(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	parentID := tl.todos[i].ParentID
+	for _, child := range tl.children(id) {
+		if err := tl.setParent(child.ID, parentID); err != nil {
+			return err
+		}
+	}
+	return tl.deleteTodo(id)
+}
+
+// liftChildren re-parents every child of a removed todo, including trashed
+// ones, to the parent of the removed todo so that no todo points to an ID
+// that may later be reused
+func (tl *TodoList) liftChildren(removed Todo) error {
+	for i := range tl.todos {
+		if tl.todos[i].ParentID != removed.ID {
+			continue
+		}
+		tl.todos[i].ParentID = removed.ParentID
+		todo := tl.todos[i]
+		if err := tl.journalAppend(JournalOp{Op: OpEdit, ID: todo.ID, At: time.Now(), Todo: &todo}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progressByTodo returns the subtask progress [done, total] of every todo
+// with subtasks among todos. Every descendant counts, not only the children.
+func progressByTodo(todos []Todo) map[int][2]int {
+	parents := make(map[int]int, len(todos))
+	for _, todo := range todos {
+		parents[todo.ID] = todo.ParentID
+	}
+
+	progress := make(map[int][2]int)
+	for _, todo := range todos {
+		seen := map[int]bool{todo.ID: true}
+		for id := todo.ParentID; id != 0 && !seen[id]; id = parents[id] {
+			if _, ok := parents[id]; !ok {
+				break
+			}
+			seen[id] = true
+			p := progress[id]
+			p[1]++
+			if todo.Completed {
+				p[0]++
+			}
+			progress[id] = p
+		}
+	}
+	return progress
+}
+
+// todoTree orders todos so that every todo directly follows its parent,
+// siblings keeping their relative order. Todos whose parent is not among
+// todos are treated as top-level. It returns the depth of each todo and
+// skips the descendants of collapsed todos.
+func todoTree(todos []Todo, collapsed map[int]bool) ([]Todo, []int) {
+	present := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		present[todo.ID] = true
+	}
+
+	children := make(map[int][]Todo)
+	var roots []Todo
+	for _, todo := range todos {
+		if todo.ParentID != 0 && present[todo.ParentID] && todo.ParentID != todo.ID {
+			children[todo.ParentID] = append(children[todo.ParentID], todo)
+		} else {
+			roots = append(roots, todo)
+		}
+	}
+
+	var ordered []Todo
+	var depths []int
+	visited := make(map[int]bool, len(todos))
+	var walk func(todo Todo, depth int, hidden bool)
+	walk = func(todo Todo, depth int, hidden bool) {
+		if visited[todo.ID] {
+			return
+		}
+		visited[todo.ID] = true
+		if !hidden {
+			ordered = append(ordered, todo)
+			depths = append(depths, depth)
+		}
+		for _, child := range children[todo.ID] {
+			walk(child, depth+1, hidden || collapsed[todo.ID])
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0, false)
+	}
+	// Todos caught in a parent cycle have no root; show them top-level
+	for _, todo := range todos {
+		walk(todo, 0, false)
+	}
+	return ordered, depths
+}
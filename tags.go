@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagPattern matches an inline tag such as "#work" at the start of the text
+// or after a space
+var tagPattern = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_\-/]+)`)
+
+// normalizeTag returns the stored form of a tag: lowercase, without "#"
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// ParseTags removes the inline tags from a description and returns the
+// remaining text and the tags found, in order and without duplicates
+func ParseTags(text string) (string, []string) {
+	var tags []string
+	for _, match := range tagPattern.FindAllStringSubmatch(text, -1) {
+		tags = addTag(tags, match[2])
+	}
+
+	cleaned := tagPattern.ReplaceAllString(text, "$1")
+	return strings.Join(strings.Fields(cleaned), " "), tags
+}
+
+// addTag appends a tag unless it is empty or already present
+func addTag(tags []string, tag string) []string {
+	tag = normalizeTag(tag)
+	if tag == "" || strings.ContainsAny(tag, ", \t") {
+		return tags
+	}
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// normalizeTags returns the tags in stored form, without duplicates
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		normalized = addTag(normalized, tag)
+	}
+	return normalized
+}
+
+// HasTag reports whether the todo carries the given tag
+func (t Todo) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range t.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTags replaces the tags of a todo
+func (tl *TodoList) SetTags(id int, tags []string) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	tl.todos[i].Tags = normalizeTags(tags)
+	todo := tl.todos[i]
+	return tl.record(OpEdit, id, &todo)
+}
+
+// GetTodosByTag returns all todos carrying the given tag
+func (tl *TodoList) GetTodosByTag(tag string) []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return filterByTag(tl.getTodos(), tag)
+}
+
+// GetTags returns every tag used by a todo of the list, sorted
+func (tl *TodoList) GetTags() []string {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, todo := range tl.getTodos() {
+		for _, tag := range todo.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// filterByTag returns the todos carrying the given tag
+func filterByTag(todos []Todo, tag string) []Todo {
+	var filtered []Todo
+	for _, todo := range todos {
+		if todo.HasTag(tag) {
+			filtered = append(filtered, todo)
+		}
+	}
+	return filtered
+}
@@ -1,185 +1,520 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Todo represents a single todo item
 type Todo struct {
-	ID          int
-	Description string
-	Completed   bool
-	CreatedAt   time.Time
+	ID          int               `json:"id"`
+	Description string            `json:"description"`
+	Completed   bool              `json:"completed"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DeletedAt   time.Time         `json:"deleted_at,omitzero"` // Set while the todo is in the trash
+	StartAt     time.Time         `json:"start_at,omitzero"`   // Optional start date
+	DueAt       time.Time         `json:"due_at,omitzero"`     // Optional due date
+	Priority    Priority          `json:"priority,omitzero"`
+	Tags        []string          `json:"tags,omitempty"`        // Lowercase, without "#"
+	ParentID    int               `json:"parent_id,omitzero"`    // Parent todo; 0 for a top-level todo
+	Recurrence  Recurrence        `json:"recurrence,omitzero"`   // Repeat rule; zero if the todo does not repeat
+	Position    int               `json:"position,omitzero"`     // Manual order among siblings; 0 (never moved) comes first
+	CompletedAt time.Time         `json:"completed_at,omitzero"` // Set while the todo is completed
+	Completions []CompletionEvent `json:"completions,omitempty"` // Completed and reopened events, oldest first
 }
 
-// TodoList manages the list of todos and file operations
+// InTrash reports whether the todo has been moved to the trash
+func (t Todo) InTrash() bool {
+	return !t.DeletedAt.IsZero()
+}
+
+// TodoList manages the list of todos and persists them through a Store.
+// It is safe for concurrent use: exported methods take the mutex and the
+// unexported helpers expect it to be held. Writes to the data files take a
+// cross-process file lock, see write.
 type TodoList struct {
-	todos    []Todo
-	filename string
-	nextID   int
+	mu         sync.Mutex
+	todos      []Todo
+	store      Store
+	project    string   // Store key of the list; "" for the main list
+	journal    *Journal // Operations not yet compacted into the snapshot
+	nextID     int
+	generation int64       // Generation of the data files when last read or written
+	stamp      listStamp   // Data files as last read or written, see ReloadIfChanged
+	base       []Todo      // Todos as last read or written, the base of merges
+	snapshot   []Todo      // Snapshot as last read or written, without the journal
+	unsaved    bool        // Changes kept in memory until merge conflicts are resolved
+	renamed    map[int]int // New IDs the merge of the last write gave to todos, see afterWrite
 }
 
-// NewTodoList creates a new TodoList instance
-func NewTodoList(filename string) *TodoList {
+// NewTodoList creates a new TodoList instance backed by the given store.
+// project is "" for the main list or the name of a project.
+func NewTodoList(store Store, project string) *TodoList {
 	tl := &TodoList{
-		todos:    []Todo{},
-		filename: filename,
-		nextID:   1,
+		todos:   []Todo{},
+		store:   store,
+		project: project,
+		journal: OpenJournal(store.JournalPath(project)),
+		nextID:  1,
 	}
 	tl.LoadFromFile()
 	return tl
 }
 
-// LoadFromFile loads todos from the text file
+// LoadFromFile loads the snapshot from the store and replays the journal
+// on top of it
 func (tl *TodoList) LoadFromFile() {
-	file, err := os.Open(tl.filename)
-	if err != nil {
-		// File doesn't exist yet, which is fine for a new todolist
-		return
-	}
-	defer file.Close()
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			// Skip empty lines and header metadata
-			continue
+	tl.load()
+	if tl.journal.Len() >= journalCompactThreshold {
+		if err := tl.compact(); err != nil {
+			fmt.Printf("⚠️ Lỗi nén journal %q: %v\n", tl.project, err)
 		}
+	}
+}
 
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) != 4 {
-			continue
+// load reads the snapshot and the journal while holding the file lock, so
+// that a write of another process is never seen half done
+func (tl *TodoList) load() {
+	lock, err := lockFile(tl.journal.path)
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	} else {
+		defer lock.Unlock()
+		if tl.generation, err = lock.Generation(); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
 		}
+	}
 
-		id, err := strconv.Atoi(parts[0])
-		if err != nil {
-			continue
-		}
+	snapshot, todos, err := tl.readFiles()
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	tl.setTodos(todos)
+	tl.snapshot = snapshot
+	tl.base = slices.Clone(tl.todos)
+	tl.stamp = tl.statFiles()
+	tl.unsaved = false
+}
 
-		completed := parts[2] == "true"
-		createdAt, err := time.Parse(time.RFC3339, parts[3])
-		if err != nil {
-			createdAt = time.Now()
-		}
+// readFiles reads the snapshot and returns it along with the todos of the
+// journal replayed on top of it. On errors it returns what could be read.
+func (tl *TodoList) readFiles() (snapshot, todos []Todo, err error) {
+	snapshot, loadErr := tl.store.LoadTodos(tl.project)
+	if loadErr != nil {
+		loadErr = fmt.Errorf("lỗi đọc danh sách %q: %v", tl.project, loadErr)
+	}
+
+	ops, journalErr := tl.journal.Load()
+	if journalErr != nil {
+		journalErr = fmt.Errorf("lỗi đọc journal %q: %v", tl.project, journalErr)
+	}
+	todos = slices.Clone(snapshot)
+	for _, op := range ops {
+		todos = applyOp(todos, op)
+	}
+	return snapshot, todos, errors.Join(loadErr, journalErr)
+}
 
-		todo := Todo{
-			ID:          id,
-			Description: parts[1],
-			Completed:   completed,
-			CreatedAt:   createdAt,
+// write runs fn, which writes a change of the list to its data files, while
+// holding the cross-process file lock. If another process or program
+// changed the files since the list last read or wrote them, their changes
+// are merged first, see mergeTodos. Todos changed on both sides are
+// returned as a *MergeConflictError and nothing is written.
+func (tl *TodoList) write(fn func() error) error {
+	return tl.writeMerged(fn, nil)
+}
+
+// writeMerged is write where keepOurs resolves merge conflicts, telling
+// whether to keep the version of this app
+func (tl *TodoList) writeMerged(fn func() error, keepOurs func(MergeConflict) bool) error {
+	lock, err := lockFile(tl.journal.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	gen, err := lock.Generation()
+	if err != nil {
+		return err
+	}
+
+	tl.renamed = nil
+	merged := false
+	if gen != tl.generation || tl.statFiles() != tl.stamp {
+		if merged, err = tl.mergeFiles(gen, keepOurs); err != nil {
+			return err
 		}
+	}
+
+	// Even a failed write may have changed the files
+	err = fn()
+	if err == nil && merged && tl.journal.Len() > 0 {
+		// The journal holds the operations of both sides; the merged list
+		// becomes the new snapshot
+		err = tl.saveSnapshot()
+	}
+	if err == nil {
+		tl.base = slices.Clone(tl.todos)
+		tl.unsaved = false
+	}
+	tl.stamp = tl.statFiles()
+	tl.generation = gen + 1
+	if genErr := lock.SetGeneration(tl.generation); err == nil {
+		err = genErr
+	}
+	return err
+}
 
+// setTodos replaces the in-memory todos and recomputes the next ID
+func (tl *TodoList) setTodos(todos []Todo) {
+	tl.todos = []Todo{}
+	tl.nextID = 1
+	for _, todo := range todos {
 		tl.todos = append(tl.todos, todo)
-		if id >= tl.nextID {
-			tl.nextID = id + 1
+		if todo.ID >= tl.nextID {
+			tl.nextID = todo.ID + 1
 		}
 	}
 }
 
-// SaveToFile saves todos to the text file while preserving header metadata
+// SaveToFile saves todos through the store
 func (tl *TodoList) SaveToFile() error {
-	// Read existing header metadata
-	var headerLines []string
-	if existingFile, err := os.Open(tl.filename); err == nil {
-		defer existingFile.Close()
-		scanner := bufio.NewScanner(existingFile)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Keep header lines (starting with #) and empty lines before todos
-			if strings.HasPrefix(line, "#") || (strings.TrimSpace(line) == "" && len(headerLines) > 0) {
-				headerLines = append(headerLines, line)
-			} else if strings.Contains(line, "|") {
-				// Stop when we hit todo data
-				break
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.write(tl.saveTodos)
+}
+
+// record appends an operation to the journal, compacting it into the
+// snapshot once it grows past the threshold
+func (tl *TodoList) record(op string, id int, todo *Todo) error {
+	return tl.journalAppend(JournalOp{Op: op, ID: id, At: time.Now(), Todo: todo})
+}
+
+// journalAppend appends a prepared journal entry, compacting the journal
+// once it grows past the threshold
+func (tl *TodoList) journalAppend(entry JournalOp) error {
+	err := tl.write(func() error {
+		// The merge may have renamed a todo added in this app
+		if newID, ok := tl.renamed[entry.ID]; ok {
+			entry.ID = newID
+			if entry.Todo != nil {
+				todo := tl.afterWrite(*entry.Todo)
+				entry.Todo = &todo
 			}
 		}
-	}
-
-	// Create new file
-	file, err := os.Create(tl.filename)
+		return tl.journal.Append(entry)
+	})
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
 
-	// Write header metadata first
-	for _, headerLine := range headerLines {
-		writer.WriteString(headerLine + "\n")
+	if tl.journal.Len() >= journalCompactThreshold {
+		return tl.compact()
 	}
+	return nil
+}
 
-	// Write todo data
-	for _, todo := range tl.todos {
-		line := fmt.Sprintf("%d|%s|%t|%s\n",
-			todo.ID,
-			todo.Description,
-			todo.Completed,
-			todo.CreatedAt.Format(time.RFC3339),
-		)
-		writer.WriteString(line)
+// Compact writes the current todos as a new snapshot and moves the journal
+// entries into the history
+func (tl *TodoList) Compact() error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.compact()
+}
+
+// compact is Compact with the mutex held
+func (tl *TodoList) compact() error {
+	return tl.write(tl.saveSnapshot)
+}
+
+// saveSnapshot writes the todos as the snapshot and compacts the journal;
+// the caller holds the file lock
+func (tl *TodoList) saveSnapshot() error {
+	if err := tl.saveTodos(); err != nil {
+		return err
 	}
+	return tl.journal.Compact()
+}
 
+// saveTodos writes the todos as the snapshot, leaving the journal alone;
+// the caller holds the file lock
+func (tl *TodoList) saveTodos() error {
+	if err := tl.store.SaveTodos(tl.project, tl.todos); err != nil {
+		return err
+	}
+	tl.snapshot = slices.Clone(tl.todos)
 	return nil
 }
 
+// History returns every recorded operation on a todo, oldest first
+func (tl *TodoList) History(id int) []JournalOp {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.journal.History(id)
+}
+
 // AddTodo adds a new todo item
 func (tl *TodoList) AddTodo(description string) error {
-	if strings.TrimSpace(description) == "" {
-		return fmt.Errorf("mô tả không được để trống")
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	_, err := tl.addTodoItem(Todo{Description: description})
+	return err
+}
+
+// AddTodoItem adds a new todo with the optional fields already set on todo.
+// The ID and creation time are assigned here; the added todo is returned.
+func (tl *TodoList) AddTodoItem(todo Todo) (Todo, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.addTodoItem(todo)
+}
+
+// addTodoItem is AddTodoItem with the mutex held
+func (tl *TodoList) addTodoItem(todo Todo) (Todo, error) {
+	todo, err := tl.insertTodoItem(todo)
+	if err != nil {
+		return Todo{}, err
 	}
+	err = tl.record(OpAdd, todo.ID, &todo)
+	return tl.afterWrite(todo), err
+}
 
-	todo := Todo{
-		ID:          tl.nextID,
-		Description: description,
-		Completed:   false,
-		CreatedAt:   time.Now(),
+// insertTodoItem validates a new todo and adds it in memory only, giving it
+// its ID and creation time
+func (tl *TodoList) insertTodoItem(todo Todo) (Todo, error) {
+	if strings.TrimSpace(todo.Description) == "" {
+		return Todo{}, fmt.Errorf("mô tả không được để trống")
+	}
+	if !todo.Priority.Valid() {
+		return Todo{}, fmt.Errorf("độ ưu tiên không hợp lệ: %d", todo.Priority)
 	}
+	if todo.ParentID != 0 && tl.index(todo.ParentID) < 0 {
+		return Todo{}, fmt.Errorf("không tìm thấy công việc cha với ID %d", todo.ParentID)
+	}
+
+	todo.ID = tl.nextID
+	todo.Completed = false
+	todo.CompletedAt = time.Time{}
+	todo.Completions = nil
+	todo.CreatedAt = time.Now()
 
 	tl.todos = append(tl.todos, todo)
 	tl.nextID++
-	return tl.SaveToFile()
+	return todo, nil
 }
 
-// MarkComplete marks a todo as completed
-func (tl *TodoList) MarkComplete(id int) error {
+// index returns the position of a todo that is not in the trash, or -1
+func (tl *TodoList) index(id int) int {
 	for i := range tl.todos {
-		if tl.todos[i].ID == id {
-			tl.todos[i].Completed = true
-			return tl.SaveToFile()
+		if tl.todos[i].ID == id && !tl.todos[i].InTrash() {
+			return i
+		}
+	}
+	return -1
+}
+
+// MarkComplete marks a todo as completed. Completing a repeating todo
+// creates its next occurrence.
+func (tl *TodoList) MarkComplete(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	_, _, err := tl.markCompleteRepeat(id)
+	return err
+}
+
+// MarkCompleteRepeat marks a todo as completed and, if it repeats, returns
+// the next occurrence that was created. The repeat rule moves to the new
+// occurrence.
+func (tl *TodoList) MarkCompleteRepeat(id int) (next Todo, repeated bool, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.markCompleteRepeat(id)
+}
+
+// markCompleteRepeat is MarkCompleteRepeat with the mutex held
+func (tl *TodoList) markCompleteRepeat(id int) (next Todo, repeated bool, err error) {
+	i := tl.index(id)
+	if i < 0 {
+		return Todo{}, false, fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	now := time.Now()
+	repeating := tl.todos[i]
+	repeated = !repeating.Recurrence.IsZero()
+	if repeated {
+		// The next occurrence is added in memory with the completion, so
+		// that a failed write keeps both until it is retried
+		if tl.index(repeating.ParentID) < 0 {
+			repeating.ParentID = 0
 		}
+		if next, err = tl.insertTodoItem(nextOccurrence(repeating, now)); err != nil {
+			return Todo{}, false, err
+		}
+		tl.todos[i].Recurrence = Recurrence{}
 	}
-	return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	tl.setCompleted(i, true, now)
+
+	completed := tl.todos[i]
+	if err := tl.record(OpComplete, id, &completed); err != nil || !repeated {
+		return Todo{}, false, err
+	}
+	next = tl.afterWrite(next)
+	err = tl.record(OpAdd, next.ID, &next)
+	return tl.afterWrite(next), true, err
+}
+
+// TodoUpdate lists the fields to change with UpdateTodo; nil fields are
+// left unchanged. A zero date clears the date and an empty Tags slice
+// removes every tag.
+type TodoUpdate struct {
+	Description *string
+	StartAt     *time.Time
+	DueAt       *time.Time
+	Priority    *Priority
+	Tags        *[]string
 }
 
-// DeleteTodo removes a todo item
+// UpdateTodo changes the editable fields of a todo, keeping its ID and
+// creation time. Every field is validated before anything is changed.
+func (tl *TodoList) UpdateTodo(id int, update TodoUpdate) (Todo, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.index(id)
+	if i < 0 {
+		return Todo{}, fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	todo := tl.todos[i]
+	if update.Description != nil {
+		todo.Description = strings.TrimSpace(*update.Description)
+		if todo.Description == "" {
+			return Todo{}, fmt.Errorf("mô tả không được để trống")
+		}
+	}
+	if update.StartAt != nil {
+		todo.StartAt = *update.StartAt
+	}
+	if update.DueAt != nil {
+		todo.DueAt = *update.DueAt
+	}
+	startAt, dueAt, err := normalizeDates(todo.StartAt, todo.DueAt)
+	if err != nil {
+		return Todo{}, err
+	}
+	todo.StartAt, todo.DueAt = startAt, dueAt
+	if update.Priority != nil {
+		if !update.Priority.Valid() {
+			return Todo{}, fmt.Errorf("độ ưu tiên không hợp lệ: %d", *update.Priority)
+		}
+		todo.Priority = *update.Priority
+	}
+	if update.Tags != nil {
+		todo.Tags = normalizeTags(*update.Tags)
+	}
+
+	tl.todos[i] = todo
+	return todo, tl.record(OpEdit, id, &todo)
+}
+
+// DeleteTodo moves a todo item to the trash
 func (tl *TodoList) DeleteTodo(id int) error {
-	for i, todo := range tl.todos {
-		if todo.ID == id {
-			tl.todos = append(tl.todos[:i], tl.todos[i+1:]...)
-			return tl.SaveToFile()
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.deleteTodo(id)
+}
+
+// deleteTodo is DeleteTodo with the mutex held
+func (tl *TodoList) deleteTodo(id int) error {
+	i := tl.index(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+	}
+
+	now := time.Now()
+	tl.todos[i].DeletedAt = now
+	return tl.journalAppend(JournalOp{Op: OpTrash, ID: id, At: now})
+}
+
+// GetTodo returns the todo with the given ID unless it is in the trash
+func (tl *TodoList) GetTodo(id int) (Todo, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if i := tl.index(id); i >= 0 {
+		return tl.todos[i], true
+	}
+	return Todo{}, false
+}
+
+// PutTodo stores a todo as given: an existing todo with the same ID is
+// replaced, otherwise the todo is inserted back at its original position.
+// It is used to revert operations, e.g. to restore a deleted todo.
+func (tl *TodoList) PutTodo(todo Todo) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	for i := range tl.todos {
+		if tl.todos[i].ID == todo.ID {
+			tl.todos[i] = todo
+			return tl.record(OpEdit, todo.ID, &todo)
 		}
 	}
-	return fmt.Errorf("không tìm thấy công việc với ID %d", id)
+
+	tl.todos = insertTodoByID(tl.todos, todo)
+	if todo.ID >= tl.nextID {
+		tl.nextID = todo.ID + 1
+	}
+	return tl.record(OpAdd, todo.ID, &todo)
 }
 
-// GetTodos returns all todos
+// GetTodos returns all todos that are not in the trash
 func (tl *TodoList) GetTodos() []Todo {
-	return tl.todos
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.getTodos()
+}
+
+// getTodos is GetTodos with the mutex held
+func (tl *TodoList) getTodos() []Todo {
+	var todos []Todo
+	for _, todo := range tl.todos {
+		if !todo.InTrash() {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
 }
 
 // GetActiveTodos returns only incomplete todos
 func (tl *TodoList) GetActiveTodos() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.activeTodos()
+}
+
+// activeTodos is GetActiveTodos with the mutex held
+func (tl *TodoList) activeTodos() []Todo {
 	var active []Todo
 	for _, todo := range tl.todos {
-		if !todo.Completed {
+		if !todo.Completed && !todo.InTrash() {
 			active = append(active, todo)
 		}
 	}
@@ -188,72 +523,126 @@ func (tl *TodoList) GetActiveTodos() []Todo {
 
 // GetCompletedTodos returns only completed todos
 func (tl *TodoList) GetCompletedTodos() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
 	var completed []Todo
 	for _, todo := range tl.todos {
-		if todo.Completed {
+		if todo.Completed && !todo.InTrash() {
 			completed = append(completed, todo)
 		}
 	}
 	return completed
 }
 
-// ProjectList extends TodoList with project-specific features
+// ProjectList extends TodoList with project-specific features. The
+// metadata is guarded by its own mutex, so the getters and setters are safe
+// for concurrent use as well.
 type ProjectList struct {
-	*TodoList              // Embedded TodoList for inheritance
-	Color           string // Project color theme
-	Theme           string // Theme name (e.g., "blue", "red", "green")
-	Name            string // Project name
-	BackgroundImage string // Background image path for project theme
+	*TodoList                 // Embedded TodoList for inheritance
+	Color           string    // Project color theme
+	Theme           string    // Theme name (e.g., "blue", "red", "green")
+	Name            string    // Project name
+	BackgroundImage string    // Background image path for project theme
+	Description     string    // Optional project description
+	Created         time.Time // When the project was created
+
+	metaMu sync.RWMutex // Guards the metadata fields above
 }
 
-// NewProjectList creates a new ProjectList instance
-func NewProjectList(filename, name, color, theme, backgroundImage string) *ProjectList {
+// NewProjectList creates a new ProjectList instance from stored metadata
+func NewProjectList(store Store, meta ProjectMeta) *ProjectList {
 	pl := &ProjectList{
-		TodoList:        NewTodoList(filename),
-		Color:           color,
-		Theme:           theme,
-		Name:            name,
-		BackgroundImage: backgroundImage,
+		TodoList:        NewTodoList(store, meta.Name),
+		Color:           meta.Color,
+		Theme:           meta.Color,
+		Name:            meta.Name,
+		BackgroundImage: meta.BackgroundImage,
+		Description:     meta.Description,
+		Created:         meta.Created,
 	}
 	return pl
 }
 
+// Meta returns the project metadata as persisted by the store
+func (pl *ProjectList) Meta() ProjectMeta {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
+	return ProjectMeta{
+		Name:            pl.Name,
+		Description:     pl.Description,
+		Color:           pl.Color,
+		BackgroundImage: pl.BackgroundImage,
+		Created:         pl.Created,
+	}
+}
+
+// SaveMeta persists the project metadata
+func (pl *ProjectList) SaveMeta() error {
+	return pl.store.UpdateProject(pl.Meta())
+}
+
 // GetColor returns the project color
 func (pl *ProjectList) GetColor() string {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
 	return pl.Color
 }
 
 // SetColor sets the project color
 func (pl *ProjectList) SetColor(color string) {
+	pl.metaMu.Lock()
+	defer pl.metaMu.Unlock()
+
 	pl.Color = color
 }
 
 // GetTheme returns the project theme
 func (pl *ProjectList) GetTheme() string {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
 	return pl.Theme
 }
 
 // SetTheme sets the project theme
 func (pl *ProjectList) SetTheme(theme string) {
+	pl.metaMu.Lock()
+	defer pl.metaMu.Unlock()
+
 	pl.Theme = theme
 }
 
 // GetName returns the project name
 func (pl *ProjectList) GetName() string {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
 	return pl.Name
 }
 
 // GetBackgroundImage returns the project background image path
 func (pl *ProjectList) GetBackgroundImage() string {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
 	return pl.BackgroundImage
 }
 
 // SetBackgroundImage sets the project background image path
 func (pl *ProjectList) SetBackgroundImage(imagePath string) {
+	pl.metaMu.Lock()
+	defer pl.metaMu.Unlock()
+
 	pl.BackgroundImage = imagePath
 }
 
 // HasBackgroundImage checks if project has a background image
 func (pl *ProjectList) HasBackgroundImage() bool {
+	pl.metaMu.RLock()
+	defer pl.metaMu.RUnlock()
+
 	return pl.BackgroundImage != ""
 }
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// todo.txt format (https://github.com/todotxt/todo.txt):
+//
+//	x 2025-10-02 2025-10-01 Mua sữa +Wee @nhà due:2025-10-05 id:3
+//
+// A line starts with "x" and the completion date when completed, or with
+// the priority "(A)" when not, followed by the creation date. +project and
+// @context words, and key:value extras follow the description. Fields of
+// the app without a todo.txt convention are written as extras whose values,
+// as the convention asks, contain no colon; exact times use the basic
+// ISO 8601 form, e.g. created:20251001T083000+0700. A description word
+// that would be read as one of these fields is escaped with a backslash,
+// e.g. \id:3 or \@home.
+const (
+	todoTxtDate = "2006-01-02"
+	todoTxtTime = "20060102T150405Z0700"
+
+	todoTxtDue       = "due"
+	todoTxtStart     = "t" // Threshold date, the common todo.txt name for a start date
+	todoTxtPriority  = "pri"
+	todoTxtID        = "id"
+	todoTxtParent    = "parent"
+	todoTxtRepeat    = "repeat"
+	todoTxtPosition  = "pos"
+	todoTxtCreated   = "created"
+	todoTxtCompleted = "done"
+	todoTxtDeleted   = "deleted"
+	todoTxtHistory   = "history"
+)
+
+// todoTxtPriorities are the letters of the urgent, high, medium and low
+// priorities
+const todoTxtPriorities = "ABCD"
+
+// todoTxtPriorityLetter returns the letter of a priority, or 0 for none
+func todoTxtPriorityLetter(p Priority) byte {
+	if p <= PriorityNone || p > PriorityUrgent {
+		return 0
+	}
+	return todoTxtPriorities[PriorityUrgent-p]
+}
+
+// parseTodoTxtPriorityWord parses a priority such as "(A)"
+func parseTodoTxtPriorityWord(word string) (Priority, bool) {
+	if len(word) != 3 || word[0] != '(' || word[2] != ')' {
+		return PriorityNone, false
+	}
+	return parseTodoTxtPriority(word[1])
+}
+
+// parseTodoTxtPriority returns the priority of a letter: A to C map to
+// urgent, high and medium, any later letter to low
+func parseTodoTxtPriority(letter byte) (Priority, bool) {
+	if letter < 'A' || letter > 'Z' {
+		return PriorityNone, false
+	}
+	if i := strings.IndexByte(todoTxtPriorities, letter); i >= 0 {
+		return PriorityUrgent - Priority(i), true
+	}
+	return PriorityLow, true
+}
+
+// todoTxtProject returns the +project word of a project; todo.txt words
+// cannot contain spaces
+func todoTxtProject(project string) string {
+	return "+" + strings.ReplaceAll(project, " ", "_")
+}
+
+// EncodeTodoTxt encodes a todo as a todo.txt line. project is the project
+// of the list, "" for the main list, and is written as +project.
+func EncodeTodoTxt(todo Todo, project string) string {
+	var words []string
+	priority := todoTxtPriorityLetter(todo.Priority)
+	if todo.Completed {
+		words = append(words, "x")
+		if !todo.CompletedAt.IsZero() {
+			words = append(words, todo.CompletedAt.Local().Format(todoTxtDate))
+		}
+	} else if priority != 0 {
+		words = append(words, "("+string(priority)+")")
+	}
+	// A lone date after "x" is the completion date; the created: extra
+	// still holds the creation time
+	if !todo.CreatedAt.IsZero() && (!todo.Completed || !todo.CompletedAt.IsZero()) {
+		words = append(words, todo.CreatedAt.Local().Format(todoTxtDate))
+	}
+
+	ownProject := ""
+	if project != "" {
+		ownProject = todoTxtProject(project)
+	}
+	for i, word := range strings.Fields(todo.Description) {
+		if todoTxtNeedsEscape(word, i == 0, ownProject) {
+			word = `\` + word
+		}
+		words = append(words, word)
+	}
+	if ownProject != "" {
+		words = append(words, ownProject)
+	}
+	for _, tag := range todo.Tags {
+		words = append(words, "@"+tag)
+	}
+
+	extra := func(key, value string) {
+		words = append(words, key+":"+value)
+	}
+	if !todo.DueAt.IsZero() {
+		extra(todoTxtDue, todo.DueAt.Local().Format(todoTxtDate))
+	}
+	if !todo.StartAt.IsZero() {
+		extra(todoTxtStart, todo.StartAt.Local().Format(todoTxtDate))
+	}
+	if todo.Completed && priority != 0 {
+		extra(todoTxtPriority, string(priority))
+	}
+	extra(todoTxtID, strconv.Itoa(todo.ID))
+	if todo.ParentID != 0 {
+		extra(todoTxtParent, strconv.Itoa(todo.ParentID))
+	}
+	if !todo.Recurrence.IsZero() {
+		extra(todoTxtRepeat, todo.Recurrence.String())
+	}
+	if todo.Position != 0 {
+		extra(todoTxtPosition, strconv.Itoa(todo.Position))
+	}
+	if !todo.CreatedAt.IsZero() {
+		extra(todoTxtCreated, todo.CreatedAt.Format(todoTxtTime))
+	}
+	if !todo.CompletedAt.IsZero() {
+		extra(todoTxtCompleted, todo.CompletedAt.Format(todoTxtTime))
+	}
+	if !todo.DeletedAt.IsZero() {
+		extra(todoTxtDeleted, todo.DeletedAt.Format(todoTxtTime))
+	}
+	if len(todo.Completions) > 0 {
+		events := make([]string, len(todo.Completions))
+		for i, event := range todo.Completions {
+			events[i] = event.Event + "@" + event.At.Format(todoTxtTime)
+		}
+		extra(todoTxtHistory, strings.Join(events, ","))
+	}
+	return strings.Join(words, " ")
+}
+
+// todoTxtNeedsEscape reports whether a description word would be read as a
+// field: a @context, the +project of the list, an extra of this app or, as
+// the first word, the completion mark, a priority or a date. Words starting
+// with the escaping backslash are escaped too.
+func todoTxtNeedsEscape(word string, first bool, ownProject string) bool {
+	if strings.HasPrefix(word, `\`) || (len(word) > 1 && word[0] == '@') {
+		return true
+	}
+	if ownProject != "" && strings.EqualFold(word, ownProject) {
+		return true
+	}
+	if key, value, ok := strings.Cut(word, ":"); ok && key != "" && value != "" && decodeTodoTxtExtra(&Todo{}, key, value) {
+		return true
+	}
+	if !first {
+		return false
+	}
+	_, priority := parseTodoTxtPriorityWord(word)
+	_, date := parseTodoTxtDate(word)
+	return word == "x" || priority || date
+}
+
+// parseTodoTxtDate parses a todo.txt date as local midnight
+func parseTodoTxtDate(value string) (time.Time, bool) {
+	t, err := time.ParseInLocation(todoTxtDate, value, time.Local)
+	return t, err == nil
+}
+
+// parseTodoTxtTime parses an exact time written by EncodeTodoTxt
+func parseTodoTxtTime(value string) (time.Time, bool) {
+	t, err := time.Parse(todoTxtTime, value)
+	return t, err == nil
+}
+
+// decodeTodoTxtExtra sets the field of a key:value extra on a todo and
+// reports whether the key is known and the value valid. Other extras stay
+// in the description, as todo.txt tools do.
+func decodeTodoTxtExtra(todo *Todo, key, value string) bool {
+	switch key {
+	case todoTxtDue, todoTxtStart:
+		date, ok := parseTodoTxtDate(value)
+		if !ok {
+			return false
+		}
+		if key == todoTxtDue {
+			todo.DueAt = date
+		} else {
+			todo.StartAt = date
+		}
+	case todoTxtPriority:
+		if len(value) != 1 {
+			return false
+		}
+		priority, ok := parseTodoTxtPriority(value[0])
+		if !ok {
+			return false
+		}
+		todo.Priority = priority
+	case todoTxtID, todoTxtParent, todoTxtPosition:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		switch key {
+		case todoTxtID:
+			todo.ID = n
+		case todoTxtParent:
+			todo.ParentID = n
+		default:
+			todo.Position = n
+		}
+	case todoTxtRepeat:
+		rule, err := ParseRecurrence(value)
+		if err != nil || rule.Validate() != nil {
+			return false
+		}
+		todo.Recurrence = rule
+	case todoTxtCreated, todoTxtCompleted, todoTxtDeleted:
+		t, ok := parseTodoTxtTime(value)
+		if !ok {
+			return false
+		}
+		switch key {
+		case todoTxtCreated:
+			todo.CreatedAt = t
+		case todoTxtCompleted:
+			todo.CompletedAt = t
+		default:
+			todo.DeletedAt = t
+		}
+	case todoTxtHistory:
+		var events []CompletionEvent
+		for _, field := range strings.Split(value, ",") {
+			name, at, found := strings.Cut(field, "@")
+			t, valid := parseTodoTxtTime(at)
+			if !found || !valid || (name != EventCompleted && name != EventReopened) {
+				return false
+			}
+			events = append(events, CompletionEvent{Event: name, At: t})
+		}
+		todo.Completions = events
+	default:
+		return false
+	}
+	return true
+}
+
+// ParseTodoTxt parses a todo.txt line. project is the project of the list
+// the todo goes to; its +project word is dropped from the description,
+// other +project words are kept. The ID is 0 unless the line has an id:
+// extra.
+func ParseTodoTxt(line, project string) (Todo, error) {
+	words := strings.Fields(line)
+	var todo Todo
+
+	if len(words) > 0 && words[0] == "x" {
+		todo.Completed = true
+		words = words[1:]
+	} else if len(words) > 0 {
+		if priority, ok := parseTodoTxtPriorityWord(words[0]); ok {
+			todo.Priority = priority
+			words = words[1:]
+		}
+	}
+
+	// A completed todo may have a completion date before the creation date
+	var dates []time.Time
+	for len(words) > 0 && len(dates) < 2 {
+		date, ok := parseTodoTxtDate(words[0])
+		if !ok || (len(dates) == 1 && !todo.Completed) {
+			break
+		}
+		dates = append(dates, date)
+		words = words[1:]
+	}
+	switch {
+	case todo.Completed && len(dates) == 2:
+		todo.CompletedAt, todo.CreatedAt = dates[0], dates[1]
+	case todo.Completed && len(dates) == 1:
+		todo.CompletedAt = dates[0]
+	case len(dates) == 1:
+		todo.CreatedAt = dates[0]
+	}
+
+	ownProject := ""
+	if project != "" {
+		ownProject = todoTxtProject(project)
+	}
+	var description []string
+	for _, word := range words {
+		switch {
+		case strings.HasPrefix(word, `\`):
+			description = append(description, word[1:])
+		case ownProject != "" && strings.EqualFold(word, ownProject):
+		case len(word) > 1 && word[0] == '@':
+			todo.Tags = addTag(todo.Tags, word[1:])
+		default:
+			key, value, ok := strings.Cut(word, ":")
+			if !ok || key == "" || value == "" || !decodeTodoTxtExtra(&todo, key, value) {
+				description = append(description, word)
+			}
+		}
+	}
+
+	todo.Description = strings.Join(description, " ")
+	if todo.Description == "" {
+		return Todo{}, fmt.Errorf("thiếu mô tả công việc")
+	}
+	if !todo.Completed {
+		todo.CompletedAt = time.Time{}
+	}
+	return todo, nil
+}
+
+// ReadTodoTxt reads the todos of a todo.txt file for a list of the given
+// project. Lines that cannot be read are reported, with their number, and
+// skipped.
+func ReadTodoTxt(r io.Reader, project string) ([]Todo, []error) {
+	var todos []Todo
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		todo, err := ParseTodoTxt(line, project)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("dòng %d: %v", n, err))
+			continue
+		}
+		todos = append(todos, todo)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return todos, errs
+}
+
+// WriteTodoTxt writes todos as a todo.txt file
+func WriteTodoTxt(w io.Writer, todos []Todo, project string) error {
+	bw := bufio.NewWriter(w)
+	for _, todo := range todos {
+		if _, err := fmt.Fprintln(bw, EncodeTodoTxt(todo, project)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
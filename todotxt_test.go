@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	created := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 10, 2, 18, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 10, 5, 0, 0, 0, 0, time.Local)
+	rule, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,WE")
+
+	tests := []struct {
+		name    string
+		todo    Todo
+		project string
+	}{
+		{"plain", Todo{ID: 1, Description: "Mua sữa", CreatedAt: created}, ""},
+		{"fields", Todo{ID: 2, Description: "Sửa xe", CreatedAt: created, DueAt: due, StartAt: due.AddDate(0, 0, -2),
+			Priority: PriorityHigh, Tags: []string{"nhà", "xe"}, ParentID: 1, Recurrence: rule, Position: 3}, "Việc nhà"},
+		{"completed", Todo{ID: 3, Description: "Gọi điện", CreatedAt: created, Completed: true, CompletedAt: completed,
+			Priority: PriorityUrgent, Completions: []CompletionEvent{{Event: EventCompleted, At: completed}}}, ""},
+		{"completed without date", Todo{ID: 4, Description: "Cũ", CreatedAt: created, Completed: true}, ""},
+		{"trashed", Todo{ID: 5, Description: "Bỏ", CreatedAt: created, DeletedAt: completed}, ""},
+		{"extras in description", Todo{ID: 6, Description: "Ghi due:2025-10-05 id:9 pri:A vào @nhà", CreatedAt: created}, ""},
+		{"own project in description", Todo{ID: 7, Description: "Xem +Việc_nhà và +Khác", CreatedAt: created}, "Việc nhà"},
+		{"backslash", Todo{ID: 8, Description: `Thư mục \tmp và \\server`, CreatedAt: created}, ""},
+		{"leading mark", Todo{ID: 9, Description: "x là ẩn số"}, ""},
+		{"leading priority", Todo{ID: 10, Description: "(B) kế hoạch"}, ""},
+		{"leading date", Todo{ID: 11, Description: "2025-12-31 tất niên"}, ""},
+		{"unknown extras", Todo{ID: 12, Description: "Họp lúc 10:30 url:https://example.com", CreatedAt: created}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line := EncodeTodoTxt(test.todo, test.project)
+			got, err := ParseTodoTxt(line, test.project)
+			if err != nil {
+				t.Fatalf("ParseTodoTxt(%q): %v", line, err)
+			}
+			if !sameTodo(&got, &test.todo) {
+				t.Errorf("round trip of %q:\n got %+v\nwant %+v", line, got, test.todo)
+			}
+		})
+	}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 10, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		line string
+		want Todo
+	}{
+		{"Mua sữa", Todo{Description: "Mua sữa"}},
+		{"(A) 2025-10-01 Gọi mẹ @nhà +Gia_đình", Todo{Description: "Gọi mẹ +Gia_đình", Priority: PriorityUrgent, CreatedAt: day(1), Tags: []string{"nhà"}}},
+		{"(E) Đọc sách", Todo{Description: "Đọc sách", Priority: PriorityLow}},
+		{"x 2025-10-03 2025-10-01 Xong rồi", Todo{Description: "Xong rồi", Completed: true, CompletedAt: day(3), CreatedAt: day(1)}},
+		{"x 2025-10-03 Xong rồi", Todo{Description: "Xong rồi", Completed: true, CompletedAt: day(3)}},
+		{"2025-10-01 2025-10-02 Ngày", Todo{Description: "2025-10-02 Ngày", CreatedAt: day(1)}},
+		{"Nộp báo cáo due:2025-10-05 t:2025-10-04 due:soon", Todo{Description: "Nộp báo cáo due:soon", DueAt: day(5), StartAt: day(4)}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseTodoTxt(test.line, "")
+		if err != nil {
+			t.Errorf("ParseTodoTxt(%q): %v", test.line, err)
+			continue
+		}
+		if !sameTodo(&got, &test.want) {
+			t.Errorf("ParseTodoTxt(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestReadTodoTxt(t *testing.T) {
+	todos := []Todo{
+		{ID: 1, Description: "Một", CreatedAt: time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{ID: 2, Description: "Hai", ParentID: 1},
+	}
+	var buf bytes.Buffer
+	if err := WriteTodoTxt(&buf, todos, ""); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("\n@nhà due:2025-10-05\n")
+
+	got, errs := ReadTodoTxt(strings.NewReader(buf.String()), "")
+	if len(got) != 2 || !sameTodos(got, todos) {
+		t.Errorf("ReadTodoTxt = %+v", got)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "dòng 4") {
+		t.Errorf("errors = %v, want one on line 4", errs)
+	}
+}
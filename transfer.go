@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxImportErrors is how many unreadable lines the import dialog lists
+const maxImportErrors = 10
+
+// AllTodos returns every todo of the list, including the ones in the trash
+func (tl *TodoList) AllTodos() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return append([]Todo(nil), tl.todos...)
+}
+
+// ImportTodos adds todos read from another file and returns them as added.
+// A todo keeps its ID unless the list already uses it; parent IDs follow
+// the new IDs, and a todo whose parent is not part of the import becomes
+// top-level. An ID below 1 is a placeholder: the todo always gets a new ID,
+// but other imported todos can name it as parent. Todos without a creation
+// time are created now.
+func (tl *TodoList) ImportTodos(todos []Todo) ([]Todo, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	used := make(map[int]bool, len(tl.todos)+len(todos))
+	for _, todo := range tl.todos {
+		used[todo.ID] = true
+	}
+
+	// Keep the free IDs first, so that a renumbered todo cannot take the
+	// ID of a todo later in the import
+	ids := make([]int, len(todos))
+	newIDs := make(map[int]int, len(todos))
+	for i, todo := range todos {
+		if todo.ID > 0 && !used[todo.ID] {
+			ids[i] = todo.ID
+			used[todo.ID] = true
+			newIDs[todo.ID] = todo.ID
+		}
+	}
+	nextID := tl.nextID
+	for i, todo := range todos {
+		if ids[i] != 0 {
+			continue
+		}
+		for used[nextID] {
+			nextID++
+		}
+		ids[i] = nextID
+		used[nextID] = true
+		if _, ok := newIDs[todo.ID]; todo.ID != 0 && !ok {
+			newIDs[todo.ID] = nextID
+		}
+	}
+
+	now := time.Now()
+	for i, todo := range todos {
+		todo.ID = ids[i]
+		todo.ParentID = newIDs[todo.ParentID]
+		if todo.ParentID == todo.ID {
+			todo.ParentID = 0
+		}
+		if todo.CreatedAt.IsZero() {
+			todo.CreatedAt = now
+		}
+		todo.Tags = normalizeTags(todo.Tags)
+
+		tl.todos = insertTodoByID(tl.todos, todo)
+		tl.nextID = max(tl.nextID, todo.ID+1)
+	}
+
+	// Every todo is in memory before the first write, so that a merge
+	// renames the ones whose ID was taken on disk meanwhile
+	added := make([]Todo, len(ids))
+	var err error
+	for i := range ids {
+		added[i] = tl.todos[slices.IndexFunc(tl.todos, func(t Todo) bool { return t.ID == ids[i] })]
+		if err == nil {
+			err = tl.record(OpAdd, added[i].ID, &added[i])
+		}
+		for j := range ids {
+			if newID, ok := tl.renamed[ids[j]]; ok {
+				ids[j] = newID
+			}
+		}
+		added[i] = tl.afterWrite(added[i])
+	}
+	return added, err
+}
+
+// removeImported permanently removes imported todos again, to undo an import
+func removeImported(list *TodoList, todos []Todo) error {
+	for i := len(todos) - 1; i >= 0; i-- {
+		remove := list.RemoveTodo
+		if todos[i].InTrash() {
+			remove = list.PurgeTodo
+		}
+		if err := remove(todos[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// showTransferDialog shows the import and export actions of the main list
+// or of the current project. Without a current project, a project can
+// still be imported from Markdown.
+func (app *TodoApp) showTransferDialog(isProject bool) {
+	noProject := isProject && app.projectList == nil
+
+	title := "📦 Nhập / Xuất: Todos"
+	if isProject {
+		title = "📦 Nhập / Xuất: " + app.currentProject
+	}
+	if noProject {
+		title = "📦 Nhập / Xuất: Projects"
+	}
+
+	// A nil export is disabled
+	var d dialog.Dialog
+	row := func(format string, export, load func()) fyne.CanvasObject {
+		exportBtn := widget.NewButton("📤 Xuất", func() {
+			d.Hide()
+			export()
+		})
+		if export == nil {
+			exportBtn.Disable()
+		}
+		importBtn := widget.NewButton("📥 Nhập", func() {
+			d.Hide()
+			load()
+		})
+		return container.NewBorder(nil, nil, widget.NewLabel(format), container.NewHBox(exportBtn, importBtn))
+	}
+
+	content := container.NewVBox()
+	switch {
+	case noProject:
+		content.Add(row("Markdown", nil, app.importMarkdown))
+	case isProject:
+		content.Add(row("todo.txt", func() { app.exportTodoTxt(true) }, func() { app.importTodoTxt(true) }))
+		content.Add(row("Markdown", app.exportMarkdown, app.importMarkdown))
+		content.Add(row("iCalendar (.ics)", func() { app.exportICal(true) }, func() { app.importICal(true) }))
+		content.Add(row("CSV", func() { app.exportBulk(true, false) }, func() { app.importBulk(true, false) }))
+		content.Add(row("JSON", func() { app.exportBulk(true, true) }, func() { app.importBulk(true, true) }))
+	default:
+		content.Add(row("todo.txt", func() { app.exportTodoTxt(false) }, func() { app.importTodoTxt(false) }))
+		content.Add(row("iCalendar (.ics)", func() { app.exportICal(false) }, func() { app.importICal(false) }))
+		content.Add(row("CSV", func() { app.exportBulk(false, false) }, func() { app.importBulk(false, false) }))
+		content.Add(row("JSON", func() { app.exportBulk(false, true) }, func() { app.importBulk(false, true) }))
+	}
+	d = dialog.NewCustom(title, "Đóng", content, app.window)
+	d.Resize(fyne.NewSize(450, 0))
+	d.Show()
+}
+
+// createTransferButton creates the button opening the import and export
+// dialog of a tab
+func (app *TodoApp) createTransferButton(isProject bool) *widget.Button {
+	return widget.NewButton("📦 Nhập / Xuất", func() {
+		app.showTransferDialog(isProject)
+	})
+}
+
+// transferFileName returns the default name of an exported file
+func (app *TodoApp) transferFileName(isProject bool, extension string) string {
+	if isProject && app.currentProject != "" {
+		return app.currentProject + extension
+	}
+	return "todos" + extension
+}
+
+// exportFile asks where to save a file and writes it with write
+func (app *TodoApp) exportFile(fileName string, write func(w io.Writer) error) {
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+
+		err = write(writer)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("không thể xuất file: %v", err), app.window)
+			return
+		}
+		app.snackbar.Show(fmt.Sprintf("Đã xuất: %s", writer.URI().Name()), "", nil)
+	}, app.window)
+	d.SetFileName(fileName)
+	d.Show()
+}
+
+// importFile asks for a file to open and reads it with read
+func (app *TodoApp) importFile(read func(r io.Reader)) {
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, app.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		read(reader)
+	}, app.window)
+	d.Show()
+}
+
+// confirmImport shows how many todos were read from a file, and the entries
+// that could not be read, before adding them to a list
+func (app *TodoApp) confirmImport(project string, todos []Todo, errs []error) {
+	target := "Todos"
+	if project != "" {
+		target = project
+	}
+
+	var problems []string
+	for i, err := range errs {
+		if i == maxImportErrors {
+			problems = append(problems, fmt.Sprintf("… và %d lỗi khác", len(errs)-maxImportErrors))
+			break
+		}
+		problems = append(problems, "⚠️ "+err.Error())
+	}
+
+	if len(todos) == 0 {
+		message := "Không có công việc nào để nhập"
+		if len(problems) > 0 {
+			message += ":\n" + strings.Join(problems, "\n")
+		}
+		dialog.ShowError(fmt.Errorf("%s", message), app.window)
+		return
+	}
+
+	content := container.NewVBox(widget.NewLabel(fmt.Sprintf("Nhập %d công việc vào %s?", len(todos), target)))
+	if len(problems) > 0 {
+		content.Add(widget.NewLabel(fmt.Sprintf("Bỏ qua %d mục không đọc được:\n%s", len(errs), strings.Join(problems, "\n"))))
+	}
+	dialog.ShowCustomConfirm("📥 Nhập công việc", "Nhập", "Hủy", content, func(confirmed bool) {
+		if confirmed {
+			app.importTodos(project, todos)
+		}
+	}, app.window)
+}
+
+// importTodos adds imported todos to a list, with undo
+func (app *TodoApp) importTodos(project string, todos []Todo) {
+	list := app.listFor(project)
+	added, err := list.ImportTodos(todos)
+	if len(added) > 0 {
+		app.undoStack.Push(Command{
+			Label: fmt.Sprintf("Nhập %d công việc", len(added)),
+			Undo: func() error {
+				return removeImported(app.listFor(project), added)
+			},
+			Redo: func() error {
+				return putTodos(app.listFor(project), added)
+			},
+		})
+	}
+
+	app.refreshAllLists()
+	if err != nil {
+		app.showListError(err)
+		return
+	}
+	app.snackbar.Show(fmt.Sprintf("Đã nhập %d công việc", len(added)), "Hoàn tác", app.undo)
+}
+
+// exportTodoTxt saves the list, including completed and trashed todos, as
+// a todo.txt file
+func (app *TodoApp) exportTodoTxt(isProject bool) {
+	project := app.projectKey(isProject)
+	todos := app.listFor(project).AllTodos()
+	app.exportFile(app.transferFileName(isProject, ".todo.txt"), func(w io.Writer) error {
+		return WriteTodoTxt(w, todos, project)
+	})
+}
+
+// importTodoTxt adds the todos of a todo.txt file to the list
+func (app *TodoApp) importTodoTxt(isProject bool) {
+	project := app.projectKey(isProject)
+	app.importFile(func(r io.Reader) {
+		todos, errs := ReadTodoTxt(r, project)
+		app.confirmImport(project, todos, errs)
+	})
+}
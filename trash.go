@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GetTrash returns the todos in the trash, most recently deleted first
+func (tl *TodoList) GetTrash() []Todo {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	var trash []Todo
+	for _, todo := range tl.todos {
+		if todo.InTrash() {
+			trash = append(trash, todo)
+		}
+	}
+
+	sort.Slice(trash, func(i, j int) bool {
+		return trash[i].DeletedAt.After(trash[j].DeletedAt)
+	})
+	return trash
+}
+
+// trashIndex returns the position of a todo in the trash, or -1
+func (tl *TodoList) trashIndex(id int) int {
+	for i := range tl.todos {
+		if tl.todos[i].ID == id && tl.todos[i].InTrash() {
+			return i
+		}
+	}
+	return -1
+}
+
+// RestoreTodo moves a todo out of the trash
+func (tl *TodoList) RestoreTodo(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	i := tl.trashIndex(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d trong thùng rác", id)
+	}
+
+	tl.todos[i].DeletedAt = time.Time{}
+	return tl.record(OpRestore, id, nil)
+}
+
+// PurgeTodo permanently deletes a todo that is in the trash
+func (tl *TodoList) PurgeTodo(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.purgeTodo(id)
+}
+
+// purgeTodo is PurgeTodo with the mutex held
+func (tl *TodoList) purgeTodo(id int) error {
+	i := tl.trashIndex(id)
+	if i < 0 {
+		return fmt.Errorf("không tìm thấy công việc với ID %d trong thùng rác", id)
+	}
+
+	removed := tl.todos[i]
+	tl.todos = append(tl.todos[:i], tl.todos[i+1:]...)
+	if err := tl.record(OpDelete, id, nil); err != nil {
+		return err
+	}
+	return tl.liftChildren(removed)
+}
+
+// RemoveTodo permanently deletes a todo without going through the trash,
+// e.g. to undo the creation of a todo
+func (tl *TodoList) RemoveTodo(id int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if err := tl.deleteTodo(id); err != nil {
+		return err
+	}
+	return tl.purgeTodo(id)
+}
+
+// EmptyTrash permanently deletes every todo in the trash
+func (tl *TodoList) EmptyTrash() error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.purgeWhere(func(Todo) bool { return true })
+}
+
+// PurgeTrash permanently deletes todos that have been in the trash for
+// longer than maxAge and returns how many were removed. A maxAge of zero
+// or less keeps everything.
+func (tl *TodoList) PurgeTrash(maxAge time.Duration) (int, error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if maxAge <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	count := 0
+	err := tl.purgeWhere(func(todo Todo) bool {
+		if todo.DeletedAt.Before(cutoff) {
+			count++
+			return true
+		}
+		return false
+	})
+	return count, err
+}
+
+// purgeWhere permanently deletes the trashed todos matching fn
+func (tl *TodoList) purgeWhere(fn func(Todo) bool) error {
+	var ids []int
+	for _, todo := range tl.todos {
+		if todo.InTrash() && fn(todo) {
+			ids = append(ids, todo.ID)
+		}
+	}
+
+	for _, id := range ids {
+		if err := tl.purgeTodo(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import "fmt"
+
+// defaultUndoLimit is the number of commands kept on the undo stack
+const defaultUndoLimit = 100
+
+// Command is an undoable operation. Do has already been performed when the
+// command is pushed; Undo reverts it and Redo performs it again.
+type Command struct {
+	Label string
+	Undo  func() error
+	Redo  func() error
+}
+
+// UndoStack keeps the history of undoable commands
+type UndoStack struct {
+	undo  []Command
+	redo  []Command
+	limit int
+}
+
+// NewUndoStack creates an undo stack keeping at most limit commands
+func NewUndoStack(limit int) *UndoStack {
+	return &UndoStack{limit: limit}
+}
+
+// Push records a command that has just been performed and clears the redo
+// history
+func (s *UndoStack) Push(cmd Command) {
+	s.undo = append(s.undo, cmd)
+	if len(s.undo) > s.limit {
+		s.undo = s.undo[len(s.undo)-s.limit:]
+	}
+	s.redo = nil
+}
+
+// CanUndo reports whether there is a command to undo
+func (s *UndoStack) CanUndo() bool {
+	return len(s.undo) > 0
+}
+
+// CanRedo reports whether there is a command to redo
+func (s *UndoStack) CanRedo() bool {
+	return len(s.redo) > 0
+}
+
+// Undo reverts the most recent command. If reverting fails the command stays
+// on the undo stack.
+func (s *UndoStack) Undo() (Command, error) {
+	if !s.CanUndo() {
+		return Command{}, fmt.Errorf("không có thao tác nào để hoàn tác")
+	}
+
+	cmd := s.undo[len(s.undo)-1]
+	if err := cmd.Undo(); err != nil {
+		return cmd, err
+	}
+
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, cmd)
+	return cmd, nil
+}
+
+// Redo performs the most recently undone command again. If it fails the
+// command stays on the redo stack.
+func (s *UndoStack) Redo() (Command, error) {
+	if !s.CanRedo() {
+		return Command{}, fmt.Errorf("không có thao tác nào để làm lại")
+	}
+
+	cmd := s.redo[len(s.redo)-1]
+	if err := cmd.Redo(); err != nil {
+		return cmd, err
+	}
+
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, cmd)
+	return cmd, nil
+}
+
+// Clear drops the whole undo and redo history
+func (s *UndoStack) Clear() {
+	s.undo = nil
+	s.redo = nil
+}
@@ -0,0 +1,1318 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"todoapp/fireworks"
+	"todoapp/lunar"
+)
+
+// listTypes are the sub-tabs shown for the main todos and for a project, in
+// display order
+var listTypes = []string{"all", "today", "upcoming", "active", "completed", "trash"}
+
+// todoView is a list widget together with the todos it currently shows
+type todoView struct {
+	list     *todoListWidget
+	todos    []Todo
+	depths   []int  // Nesting depth of each todo; nil for flat lists
+	sortMode string // "" keeps the order of the query, e.g. for the trash
+
+	sortSelect *widget.Select // Sort selector above the list; nil for the trash
+	tabItem    *container.TabItem
+
+	filter     *Query         // Query of a smart list; nil for the built-in lists
+	filterErr  error          // Why the query of a smart list could not be parsed
+	results    []SearchResult // Todos shown by a smart list, from every list
+	resultList *widget.List   // Rows of a smart list, shown instead of list
+}
+
+// listTab holds the list views and the tag filter of the Todos tab or of
+// the Projects tab
+type listTab struct {
+	views     map[string]*todoView // Keyed by list type
+	tagFilter string               // Only todos with this tag are shown; "" shows all
+	tagBar    *fyne.Container      // Tag filter buttons
+	collapsed map[int]bool         // Todos whose subtasks are hidden
+	progress  map[int][2]int       // Subtask progress [done, total] of parent todos
+	subTabs   *container.AppTabs   // Sub-tabs showing the views
+
+	selectedID   int    // Last selected todo, edited with F2
+	selectedIn   string // List type of the last selected todo
+	editingID    int    // Todo shown with the inline editor; 0 if none
+	editingIn    string // List type showing the inline editor
+	editText     string // Current text of the inline editor
+	editFocusing bool   // The inline editor still has to take the focus
+}
+
+// todoListWidget is a list of todos that opens the inline editor of the
+// selected todo on F2
+type todoListWidget struct {
+	widget.List
+	onEdit func()
+}
+
+// TypedKey opens the inline editor on F2 and otherwise keeps the keyboard
+// navigation of the list
+func (l *todoListWidget) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyF2 && l.onEdit != nil {
+		l.onEdit()
+		return
+	}
+	l.List.TypedKey(key)
+}
+
+// todoLabel is the description of a todo card; a double-click opens the
+// inline editor
+type todoLabel struct {
+	widget.Label
+	onTapped       func()
+	onDoubleTapped func()
+}
+
+// newTodoLabel creates a bold, wrapping description label
+func newTodoLabel(text string, onTapped, onDoubleTapped func()) *todoLabel {
+	label := &todoLabel{onTapped: onTapped, onDoubleTapped: onDoubleTapped}
+	label.Text = text
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	label.Wrapping = fyne.TextWrapWord
+	label.ExtendBaseWidget(label)
+	return label
+}
+
+// Tapped selects the row, like a tap anywhere else on the card
+func (l *todoLabel) Tapped(*fyne.PointEvent) {
+	if l.onTapped != nil {
+		l.onTapped()
+	}
+}
+
+// DoubleTapped opens the inline editor
+func (l *todoLabel) DoubleTapped(*fyne.PointEvent) {
+	if l.onDoubleTapped != nil {
+		l.onDoubleTapped()
+	}
+}
+
+// inlineEntry is the entry of the inline editor; Escape cancels the edit
+type inlineEntry struct {
+	widget.Entry
+	onCancel func()
+}
+
+// newInlineEntry creates the entry of the inline editor
+func newInlineEntry(onCancel func()) *inlineEntry {
+	entry := &inlineEntry{onCancel: onCancel}
+	entry.ExtendBaseWidget(entry)
+	return entry
+}
+
+// TypedKey cancels the edit on Escape
+func (e *inlineEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyEscape && e.onCancel != nil {
+		e.onCancel()
+		return
+	}
+	e.Entry.TypedKey(key)
+}
+
+// listTab returns the Todos tab or the Projects tab, creating its list
+// widgets on first use
+func (app *TodoApp) listTab(isProject bool) *listTab {
+	tab := &app.todoTab
+	if isProject {
+		tab = &app.projectTab
+	}
+
+	if *tab == nil {
+		*tab = &listTab{
+			views:     make(map[string]*todoView),
+			tagBar:    container.NewHBox(),
+			collapsed: make(map[int]bool),
+		}
+		for _, listType := range app.listTypes() {
+			(*tab).views[listType] = app.newView(listType, isProject)
+		}
+	}
+	return *tab
+}
+
+// views returns the list views of the Todos tab or of the Projects tab
+func (app *TodoApp) views(isProject bool) map[string]*todoView {
+	return app.listTab(isProject).views
+}
+
+// createSubTabs builds the sub-tabs showing the list views of a tab
+func (app *TodoApp) createSubTabs(isProject bool) *container.AppTabs {
+	views := app.views(isProject)
+
+	subTabs := container.NewAppTabs()
+	for _, listType := range app.listTypes() {
+		subTabs.Append(app.createSubTab(views[listType], listType, isProject))
+	}
+	app.listTab(isProject).subTabs = subTabs
+	return subTabs
+}
+
+// newView creates the view of a list type
+func (app *TodoApp) newView(listType string, isProject bool) *todoView {
+	view := &todoView{sortMode: defaultSortMode(listType)}
+	if name, ok := smartListName(listType); ok {
+		app.setSmartListQuery(view, name)
+		view.resultList = app.createSmartResultList(view)
+		return view
+	}
+	view.list = app.createList(listType, isProject)
+	return view
+}
+
+// createSubTab creates the sub-tab showing a view
+func (app *TodoApp) createSubTab(view *todoView, listType string, isProject bool) *container.TabItem {
+	var content fyne.CanvasObject
+	switch {
+	case listType == "trash":
+		content = app.createTrashTabContent(view.list, isProject)
+	case view.resultList != nil:
+		content = container.NewBorder(
+			app.createSmartListHeader(view, listType),
+			nil, nil, nil,
+			view.resultList,
+		)
+	default:
+		content = container.NewBorder(
+			app.createSortSelector(view),
+			nil, nil, nil,
+			container.NewScroll(view.list),
+		)
+	}
+	view.tabItem = container.NewTabItem(listTypeTitle(listType), content)
+	return view.tabItem
+}
+
+// createSortSelector creates the sort mode selector shown above a list
+func (app *TodoApp) createSortSelector(view *todoView) fyne.CanvasObject {
+	var labels []string
+	for _, mode := range sortModes {
+		labels = append(labels, sortModeLabel(mode))
+	}
+
+	sortSelect := widget.NewSelect(labels, func(selected string) {
+		for _, mode := range sortModes {
+			if sortModeLabel(mode) == selected && mode != view.sortMode {
+				view.sortMode = mode
+				app.refreshAllLists()
+			}
+		}
+	})
+	sortSelect.SetSelected(sortModeLabel(view.sortMode))
+	view.sortSelect = sortSelect
+
+	return container.NewHBox(widget.NewLabel("Sắp xếp:"), sortSelect)
+}
+
+// defaultSortMode returns the sort mode a list type starts with
+func defaultSortMode(listType string) string {
+	switch listType {
+	case "today", "upcoming":
+		return SortDue
+	case "trash":
+		return ""
+	case "all":
+		return SortManual
+	}
+	return SortCreated
+}
+
+// listTypeTitle returns the sub-tab title of a list type
+func listTypeTitle(listType string) string {
+	switch listType {
+	case "all":
+		return "Tất cả"
+	case "today":
+		return "📅 Hôm nay"
+	case "upcoming":
+		return "🗓️ Sắp tới"
+	case "active":
+		return "Chưa hoàn thành"
+	case "completed":
+		return "Đã hoàn thành"
+	case "trash":
+		return "🗑️ Thùng rác"
+	}
+	if name, ok := smartListName(listType); ok {
+		return "🔎 " + name
+	}
+	return listType
+}
+
+// listTypeTodos returns the todos of a list shown by a list type
+func listTypeTodos(list *TodoList, listType string) []Todo {
+	switch listType {
+	case "all":
+		return list.GetTodos()
+	case "today":
+		return list.GetTodayTodos()
+	case "upcoming":
+		return list.GetUpcomingTodos()
+	case "active":
+		return list.GetActiveTodos()
+	case "completed":
+		return list.GetCompletedTodos()
+	case "trash":
+		return list.GetTrash()
+	}
+	return nil
+}
+
+// refreshViews reloads the todos of every view of a tab from list; a nil
+// list empties the views
+func (app *TodoApp) refreshViews(isProject bool, list *TodoList) {
+	tab := app.listTab(isProject)
+
+	var tags []string
+	tab.progress = nil
+	if list != nil {
+		tags = list.GetTags()
+		tab.progress = progressByTodo(list.GetTodos())
+	}
+	app.refreshTagBar(tab, tags)
+
+	for listType, view := range tab.views {
+		if view.resultList != nil {
+			app.refreshSmartList(view, tab.tagFilter)
+			continue
+		}
+
+		view.todos = nil
+		view.depths = nil
+		if list != nil {
+			view.todos = listTypeTodos(list, listType)
+			if tab.tagFilter != "" {
+				view.todos = filterByTag(view.todos, tab.tagFilter)
+			}
+			if view.sortMode != "" {
+				sortTodos(view.todos, view.sortMode)
+				// Subtasks follow their parent
+				view.todos, view.depths = todoTree(view.todos, tab.collapsed)
+			}
+		}
+		view.list.Refresh()
+	}
+}
+
+// createSubtaskToggle returns the indentation of a row and, for a todo with
+// subtasks, a button that collapses or expands them
+func (app *TodoApp) createSubtaskToggle(todo Todo, listType string, row int, isProject bool) fyne.CanvasObject {
+	tab := app.listTab(isProject)
+
+	depth := 0
+	if view := tab.views[listType]; view != nil && row < len(view.depths) {
+		depth = view.depths[row]
+	}
+	indent := canvas.NewRectangle(color.Transparent)
+	indent.SetMinSize(fyne.NewSize(float32(depth)*24, 0))
+
+	if tab.progress[todo.ID][1] == 0 {
+		return indent
+	}
+
+	icon := "▾"
+	if tab.collapsed[todo.ID] {
+		icon = "▸"
+	}
+	toggle := widget.NewButton(icon, func() {
+		if tab.collapsed[todo.ID] {
+			delete(tab.collapsed, todo.ID)
+		} else {
+			tab.collapsed[todo.ID] = true
+		}
+		app.refreshAllLists()
+	})
+	toggle.Importance = widget.LowImportance
+	return container.NewHBox(indent, toggle)
+}
+
+// createProgressLabel returns the subtask progress of a todo, or nil if it
+// has no subtasks
+func (app *TodoApp) createProgressLabel(todo Todo, isProject bool) *widget.Label {
+	progress := app.listTab(isProject).progress[todo.ID]
+	if progress[1] == 0 {
+		return nil
+	}
+
+	label := widget.NewLabel(fmt.Sprintf("☑️ %d/%d việc con", progress[0], progress[1]))
+	if progress[0] == progress[1] {
+		label.Importance = widget.SuccessImportance
+	}
+	return label
+}
+
+// showAddSubtaskDialog asks for the description of a new subtask
+func (app *TodoApp) showAddSubtaskDialog(parent Todo, isProject bool) {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Nhập công việc con...")
+
+	form := []*widget.FormItem{
+		widget.NewFormItem("Công việc", entry),
+	}
+
+	dialog.ShowForm("➕ Việc con của: "+parent.Description, "Thêm", "Hủy", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		app.addTodoItem(Todo{Description: entry.Text, ParentID: parent.ID}, isProject)
+	}, app.window)
+}
+
+// incompleteDescendants returns the unfinished subtasks of a todo
+func incompleteDescendants(list *TodoList, id int) []Todo {
+	var incomplete []Todo
+	for _, todo := range list.Descendants(id) {
+		if !todo.Completed {
+			incomplete = append(incomplete, todo)
+		}
+	}
+	return incomplete
+}
+
+// confirmCompleteParent asks whether the unfinished subtasks of a todo should
+// be completed together with it
+func (app *TodoApp) confirmCompleteParent(todoID int, count int, isProject bool) {
+	confirm := dialog.NewCustomConfirm("Công việc con",
+		"Hoàn thành tất cả", "Chỉ việc này",
+		widget.NewLabel(fmt.Sprintf("Còn %d công việc con chưa xong.\nHoàn thành luôn các công việc con?", count)),
+		func(withChildren bool) {
+			app.completeParent(todoID, withChildren, isProject)
+		}, app.window)
+	confirm.Show()
+}
+
+// completeParent marks a todo with subtasks as completed, optionally
+// together with its unfinished subtasks
+func (app *TodoApp) completeParent(todoID int, withChildren bool, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	// Keep the previous state of every affected todo for undo
+	parent, ok := list.GetTodo(todoID)
+	if !ok {
+		dialog.ShowError(fmt.Errorf("không tìm thấy công việc với ID %d", todoID), app.window)
+		return
+	}
+	previous := []Todo{parent}
+	if withChildren {
+		previous = append(previous, incompleteDescendants(list, todoID)...)
+	}
+
+	// Repeating todos create their next occurrence, which undo removes
+	var spawned []int
+	complete := func(list *TodoList) (err error) {
+		if withChildren {
+			spawned, err = list.CompleteWithChildren(todoID)
+			return err
+		}
+		next, repeated, err := list.MarkCompleteRepeat(todoID)
+		spawned = nil
+		if repeated {
+			spawned = []int{next.ID}
+		}
+		return err
+	}
+	if err := complete(list); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Hoàn thành: %s", parent.Description),
+		Undo: func() error {
+			list := app.listFor(project)
+			for _, id := range spawned {
+				if err := list.RemoveTodo(id); err != nil {
+					return err
+				}
+			}
+			return putTodos(list, previous)
+		},
+		Redo: func() error {
+			return complete(app.listFor(project))
+		},
+	})
+
+	app.refreshAllLists()
+	fireworks.ShowFireworksDialog(parent.Description, app.window)
+}
+
+// confirmDeleteParent asks whether the subtasks of a deleted todo go to the
+// trash with it or move up one level
+func (app *TodoApp) confirmDeleteParent(parent Todo, isProject bool) {
+	var choice dialog.Dialog
+
+	cascadeBtn := widget.NewButton("🗑️ Xóa cả công việc con", func() {
+		choice.Hide()
+		app.deleteParent(parent, true, isProject)
+	})
+	cascadeBtn.Importance = widget.DangerImportance
+
+	keepBtn := widget.NewButton("↩️ Giữ lại công việc con", func() {
+		choice.Hide()
+		app.deleteParent(parent, false, isProject)
+	})
+
+	count := len(app.listFor(app.projectKey(isProject)).Descendants(parent.ID))
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("'%s' có %d công việc con.", parent.Description, count)),
+		widget.NewSeparator(),
+		cascadeBtn,
+		keepBtn,
+	)
+
+	choice = dialog.NewCustom("Xóa công việc", "Hủy", content, app.window)
+	choice.Show()
+}
+
+// deleteParent moves a todo with subtasks to the trash, together with its
+// subtasks or after moving them up to its own parent
+func (app *TodoApp) deleteParent(parent Todo, withChildren bool, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	children := list.Children(parent.ID)
+	descendants := list.Descendants(parent.ID)
+
+	remove := func(list *TodoList) error {
+		if withChildren {
+			return list.DeleteWithChildren(parent.ID)
+		}
+		return list.DeleteKeepChildren(parent.ID)
+	}
+	if err := remove(list); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Xóa: %s", parent.Description),
+		Undo: func() error {
+			list := app.listFor(project)
+			if err := list.RestoreTodo(parent.ID); err != nil {
+				return err
+			}
+			if withChildren {
+				for _, todo := range descendants {
+					if err := list.RestoreTodo(todo.ID); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			return putTodos(list, children)
+		},
+		Redo: func() error {
+			return remove(app.listFor(project))
+		},
+	})
+
+	app.refreshAllLists()
+	if withChildren {
+		app.showUndoSnackbar(fmt.Sprintf("Đã chuyển vào thùng rác: %s và %d công việc con", parent.Description, len(descendants)))
+	} else {
+		app.showUndoSnackbar(fmt.Sprintf("Đã chuyển vào thùng rác: %s", parent.Description))
+	}
+}
+
+// putTodos stores the given todos as they were, e.g. to undo a change that
+// touched several todos
+func putTodos(list *TodoList, todos []Todo) error {
+	for _, todo := range todos {
+		if err := list.PutTodo(todo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTagFilterBar returns the tag filter bar shown above the sub-tabs
+func (app *TodoApp) createTagFilterBar(isProject bool) fyne.CanvasObject {
+	tab := app.listTab(isProject)
+	return container.NewHScroll(tab.tagBar)
+}
+
+// refreshTagBar rebuilds the tag filter buttons from the tags in use. A
+// filter on a tag that is no longer used is dropped.
+func (app *TodoApp) refreshTagBar(tab *listTab, tags []string) {
+	if tab.tagFilter != "" {
+		found := false
+		for _, tag := range tags {
+			found = found || tag == tab.tagFilter
+		}
+		if !found {
+			tab.tagFilter = ""
+		}
+	}
+
+	tab.tagBar.RemoveAll()
+	if len(tags) == 0 {
+		tab.tagBar.Refresh()
+		return
+	}
+
+	tab.tagBar.Add(widget.NewLabel("🏷️"))
+	allBtn := widget.NewButton("Tất cả thẻ", func() {
+		app.setTagFilter(tab, "")
+	})
+	if tab.tagFilter == "" {
+		allBtn.Importance = widget.HighImportance
+	}
+	tab.tagBar.Add(allBtn)
+
+	for _, tag := range tags {
+		tagBtn := widget.NewButton("#"+tag, func() {
+			app.setTagFilter(tab, tag)
+		})
+		if tab.tagFilter == tag {
+			tagBtn.Importance = widget.HighImportance
+		}
+		tab.tagBar.Add(tagBtn)
+	}
+	tab.tagBar.Refresh()
+}
+
+// setTagFilter shows only the todos of a tab carrying the given tag; an
+// empty tag, or the tag already selected, clears the filter
+func (app *TodoApp) setTagFilter(tab *listTab, tag string) {
+	if tab.tagFilter == tag {
+		tag = ""
+	}
+	tab.tagFilter = tag
+	app.refreshAllLists()
+}
+
+// createTagChips returns the tags of a todo as small buttons that filter
+// the tab by that tag, or nil if the todo has no tags
+func (app *TodoApp) createTagChips(todo Todo, isProject bool) fyne.CanvasObject {
+	if len(todo.Tags) == 0 {
+		return nil
+	}
+
+	tab := app.listTab(isProject)
+	chips := container.NewHBox()
+	for _, tag := range todo.Tags {
+		chip := widget.NewButton("#"+tag, func() {
+			app.setTagFilter(tab, tag)
+		})
+		chip.Importance = widget.LowImportance
+		chips.Add(chip)
+	}
+	return chips
+}
+
+// showTagsDialog lets the user edit the tags of a todo
+func (app *TodoApp) showTagsDialog(todo Todo, isProject bool) {
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("#cong-viec #nha")
+	if len(todo.Tags) > 0 {
+		tagsEntry.SetText("#" + strings.Join(todo.Tags, " #"))
+	}
+
+	form := []*widget.FormItem{
+		widget.NewFormItem("Thẻ", tagsEntry),
+	}
+
+	dialog.ShowForm("🏷️ Thẻ: "+todo.Description, "Lưu", "Hủy", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		app.setTodoTags(todo.ID, strings.FieldsFunc(tagsEntry.Text, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		}), isProject)
+	}, app.window)
+}
+
+// setTodoTags replaces the tags of a todo
+func (app *TodoApp) setTodoTags(todoID int, tags []string, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	previous, _ := list.GetTodo(todoID)
+	if err := list.SetTags(todoID, tags); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Đổi thẻ: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).SetTags(todoID, tags)
+		},
+	})
+
+	app.refreshAllLists()
+}
+
+// newPrioritySelect creates a priority selector set to PriorityNone
+func newPrioritySelect() *widget.Select {
+	var labels []string
+	for p := PriorityNone; p <= PriorityUrgent; p++ {
+		labels = append(labels, p.Label())
+	}
+
+	prioritySelect := widget.NewSelect(labels, nil)
+	prioritySelect.SetSelected(PriorityNone.Label())
+	return prioritySelect
+}
+
+// priorityColor returns the marker color of a priority
+func priorityColor(priority Priority) color.Color {
+	switch priority {
+	case PriorityLow:
+		return color.NRGBA{R: 0x4a, G: 0x90, B: 0xd9, A: 0xff}
+	case PriorityMedium:
+		return color.NRGBA{R: 0xf5, G: 0xc5, B: 0x23, A: 0xff}
+	case PriorityHigh:
+		return color.NRGBA{R: 0xf5, G: 0x6a, B: 0x23, A: 0xff}
+	case PriorityUrgent:
+		return color.NRGBA{R: 0xd0, G: 0x02, B: 0x1b, A: 0xff}
+	}
+	return color.Transparent
+}
+
+// createPriorityMarker returns the colored bar shown at the left of a card
+func createPriorityMarker(priority Priority) fyne.CanvasObject {
+	marker := canvas.NewRectangle(priorityColor(priority))
+	marker.SetMinSize(fyne.NewSize(6, 0))
+	marker.CornerRadius = 3
+	return marker
+}
+
+// showPriorityDialog lets the user change the priority of a todo
+func (app *TodoApp) showPriorityDialog(todo Todo, isProject bool) {
+	prioritySelect := newPrioritySelect()
+	prioritySelect.SetSelected(todo.Priority.Label())
+
+	form := []*widget.FormItem{
+		widget.NewFormItem("Độ ưu tiên", prioritySelect),
+	}
+
+	dialog.ShowForm("⭐ Độ ưu tiên: "+todo.Description, "Lưu", "Hủy", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		app.setTodoPriority(todo.ID, ParsePriorityLabel(prioritySelect.Selected), isProject)
+	}, app.window)
+}
+
+// setTodoPriority changes the priority of a todo
+func (app *TodoApp) setTodoPriority(todoID int, priority Priority, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	previous, _ := list.GetTodo(todoID)
+	if err := list.SetPriority(todoID, priority); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Đổi độ ưu tiên: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).SetPriority(todoID, priority)
+		},
+	})
+
+	app.refreshAllLists()
+}
+
+// lunarSuffix returns the lunar date of t to show after a solar date, or ""
+// unless lunar dates are enabled in the settings
+func (app *TodoApp) lunarSuffix(t time.Time) string {
+	if !app.config.ShowLunarDates || t.IsZero() {
+		return ""
+	}
+	return " (ÂL " + lunar.FromSolar(t.Local()).String() + ")"
+}
+
+// createDueLabel returns a label with the start and due dates of a todo,
+// colored by how close the due date is, or nil if the todo has no dates
+func (app *TodoApp) createDueLabel(todo Todo) *widget.Label {
+	if todo.DueAt.IsZero() && todo.StartAt.IsZero() {
+		return nil
+	}
+
+	label := widget.NewLabel("")
+	label.TextStyle = fyne.TextStyle{Italic: true}
+
+	var text string
+	if !todo.StartAt.IsZero() {
+		text = "▶️ Bắt đầu: " + todo.StartAt.Format("02/01/2006") + app.lunarSuffix(todo.StartAt)
+	}
+	if !todo.DueAt.IsZero() {
+		if text != "" {
+			text += "  "
+		}
+		switch todo.DueStatusAt(time.Now()) {
+		case DueOverdue:
+			text += "⏰ Quá hạn: " + todo.DueAt.Format("02/01/2006")
+			label.Importance = widget.DangerImportance
+		case DueToday:
+			text += "📅 Hạn: hôm nay"
+			label.Importance = widget.WarningImportance
+		default:
+			text += "🗓️ Hạn: " + todo.DueAt.Format("02/01/2006")
+		}
+		text += app.lunarSuffix(todo.DueAt)
+	}
+
+	label.SetText(text)
+	return label
+}
+
+// showDatesDialog lets the user set or clear the start and due dates of a todo
+func (app *TodoApp) showDatesDialog(todo Todo, isProject bool) {
+	startEntry := widget.NewDateEntry()
+	if !todo.StartAt.IsZero() {
+		startAt := todo.StartAt
+		startEntry.SetDate(&startAt)
+	}
+
+	dueEntry := widget.NewDateEntry()
+	if !todo.DueAt.IsZero() {
+		dueAt := todo.DueAt
+		dueEntry.SetDate(&dueAt)
+	}
+
+	clearStartBtn := widget.NewButton("✖", func() {
+		startEntry.SetDate(nil)
+	})
+	clearDueBtn := widget.NewButton("✖", func() {
+		dueEntry.SetDate(nil)
+	})
+
+	// Lunar date of the chosen due date
+	lunarLabel := widget.NewLabel("")
+	updateLunarLabel := func(date *time.Time) {
+		if date == nil {
+			lunarLabel.SetText("")
+			return
+		}
+		lunarDate := lunar.FromSolar(*date)
+		lunarLabel.SetText(fmt.Sprintf("%s năm %s", lunarDate.Describe(), lunar.YearName(lunarDate.Year)))
+	}
+	dueEntry.OnChanged = updateLunarLabel
+	updateLunarLabel(dueEntry.Date)
+
+	form := []*widget.FormItem{
+		widget.NewFormItem("Bắt đầu", container.NewBorder(nil, nil, nil, clearStartBtn, startEntry)),
+		widget.NewFormItem("Hạn chót", container.NewBorder(nil, nil, nil, clearDueBtn, dueEntry)),
+		widget.NewFormItem("Âm lịch", lunarLabel),
+	}
+
+	dialog.ShowForm("📅 Ngày: "+todo.Description, "Lưu", "Hủy", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		var startAt, dueAt time.Time
+		if startEntry.Date != nil {
+			startAt = *startEntry.Date
+		}
+		if dueEntry.Date != nil {
+			dueAt = *dueEntry.Date
+		}
+		app.setTodoDates(todo.ID, startAt, dueAt, isProject)
+	}, app.window)
+}
+
+// setTodoDates changes the start and due dates of a todo
+func (app *TodoApp) setTodoDates(todoID int, startAt, dueAt time.Time, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	previous, _ := list.GetTodo(todoID)
+	if err := list.SetDates(todoID, startAt, dueAt); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Đổi ngày: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).SetDates(todoID, startAt, dueAt)
+		},
+	})
+
+	app.refreshAllLists()
+}
+
+// recurrenceFreqs are the repeat kinds offered in the repeat dialog; ""
+// means the todo does not repeat
+var recurrenceFreqs = []string{"", RepeatDaily, RepeatWeekly, RepeatMonthly, RepeatAfter, RepeatLunarMonthly, RepeatLunarYearly}
+
+// recurrenceFreqLabel returns the name of a repeat kind shown in the
+// interface
+func recurrenceFreqLabel(freq string) string {
+	switch freq {
+	case RepeatDaily:
+		return "Hằng ngày"
+	case RepeatWeekly:
+		return "Hằng tuần"
+	case RepeatMonthly:
+		return "Hằng tháng"
+	case RepeatLunarMonthly:
+		return "Hằng tháng (âm lịch)"
+	case RepeatLunarYearly:
+		return "Hằng năm (âm lịch)"
+	case RepeatAfter:
+		return "Sau khi hoàn thành"
+	}
+	return "Không lặp"
+}
+
+// showRecurrenceDialog lets the user set or clear the repeat rule of a todo
+func (app *TodoApp) showRecurrenceDialog(todo Todo, isProject bool) {
+	rule := todo.Recurrence
+
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetText(strconv.Itoa(max(rule.Interval, 1)))
+	intervalUnit := widget.NewLabel("")
+
+	// Weekdays, Monday first
+	var weekdayOptions []string
+	for i := 1; i <= 7; i++ {
+		weekdayOptions = append(weekdayOptions, weekdayLabels[i%7])
+	}
+	weekdayGroup := widget.NewCheckGroup(weekdayOptions, nil)
+	weekdayGroup.Horizontal = true
+	for _, day := range rule.Weekdays {
+		weekdayGroup.Selected = append(weekdayGroup.Selected, weekdayLabels[day])
+	}
+
+	monthDaysEntry := widget.NewEntry()
+	monthDaysEntry.SetPlaceHolder("vd. 1, 15")
+	monthDaysEntry.SetText(formatNumberList(rule.MonthDays))
+
+	// Lunar months of a yearly lunar rule, e.g. 12 for tháng Chạp
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetPlaceHolder("vd. 12")
+	monthsEntry.SetText(formatNumberList(rule.Months))
+
+	intervalRow := container.NewBorder(nil, nil, widget.NewLabel("Mỗi"), intervalUnit, intervalEntry)
+	weekdayRow := container.NewVBox(widget.NewLabel("Vào các ngày:"), weekdayGroup)
+	monthDaysRow := container.NewBorder(nil, nil, widget.NewLabel("Ngày trong tháng:"), nil, monthDaysEntry)
+	monthsRow := container.NewBorder(nil, nil, widget.NewLabel("Tháng âm lịch:"), nil, monthsEntry)
+
+	var freqLabels []string
+	for _, freq := range recurrenceFreqs {
+		freqLabels = append(freqLabels, recurrenceFreqLabel(freq))
+	}
+	selectedFreq := rule.Freq
+	freqSelect := widget.NewSelect(freqLabels, func(selected string) {
+		for _, freq := range recurrenceFreqs {
+			if recurrenceFreqLabel(freq) == selected {
+				selectedFreq = freq
+			}
+		}
+
+		// Only show the fields used by the selected kind
+		intervalRow.Hidden = selectedFreq == ""
+		weekdayRow.Hidden = selectedFreq != RepeatWeekly
+		monthDaysRow.Hidden = selectedFreq != RepeatMonthly && selectedFreq != RepeatLunarMonthly && selectedFreq != RepeatLunarYearly
+		monthsRow.Hidden = selectedFreq != RepeatLunarYearly
+		switch selectedFreq {
+		case RepeatWeekly:
+			intervalUnit.SetText("tuần")
+		case RepeatMonthly, RepeatLunarMonthly:
+			intervalUnit.SetText("tháng")
+		case RepeatLunarYearly:
+			intervalUnit.SetText("năm")
+		default:
+			intervalUnit.SetText("ngày")
+		}
+		intervalRow.Refresh()
+		weekdayRow.Refresh()
+		monthDaysRow.Refresh()
+		monthsRow.Refresh()
+	})
+	freqSelect.SetSelected(recurrenceFreqLabel(rule.Freq))
+
+	content := container.NewVBox(freqSelect, intervalRow, weekdayRow, monthDaysRow, monthsRow)
+
+	dialog.ShowCustomConfirm("🔁 Lặp lại: "+todo.Description, "Lưu", "Hủy", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		newRule := Recurrence{Freq: selectedFreq}
+		if selectedFreq != "" {
+			interval, err := strconv.Atoi(strings.TrimSpace(intervalEntry.Text))
+			if err != nil || interval < 1 {
+				dialog.ShowError(fmt.Errorf("chu kỳ lặp phải là số lớn hơn 0"), app.window)
+				return
+			}
+			newRule.Interval = interval
+		}
+
+		switch selectedFreq {
+		case RepeatWeekly:
+			for _, label := range weekdayGroup.Selected {
+				for day, l := range weekdayLabels {
+					if l == label {
+						newRule.Weekdays = append(newRule.Weekdays, time.Weekday(day))
+					}
+				}
+			}
+		case RepeatMonthly, RepeatLunarMonthly, RepeatLunarYearly:
+			days, err := parseNumberList(monthDaysEntry.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("ngày trong tháng không hợp lệ: %v", err), app.window)
+				return
+			}
+			newRule.MonthDays = days
+		}
+		if selectedFreq == RepeatLunarYearly {
+			months, err := parseNumberList(monthsEntry.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("tháng âm lịch không hợp lệ: %v", err), app.window)
+				return
+			}
+			newRule.Months = months
+		}
+
+		app.setTodoRecurrence(todo.ID, newRule, isProject)
+	}, app.window)
+}
+
+// setTodoRecurrence changes the repeat rule of a todo
+func (app *TodoApp) setTodoRecurrence(todoID int, rule Recurrence, isProject bool) {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	previous, _ := list.GetTodo(todoID)
+	if err := list.SetRecurrence(todoID, rule); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Đổi lặp lại: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).SetRecurrence(todoID, rule)
+		},
+	})
+
+	app.refreshAllLists()
+}
+
+// formatNumberList formats numbers as "1, 15"
+func formatNumberList(numbers []int) string {
+	var fields []string
+	for _, n := range numbers {
+		fields = append(fields, strconv.Itoa(n))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// parseNumberList parses numbers separated by commas or spaces
+func parseNumberList(text string) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%q", field)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// editableText returns the description of a todo followed by its tags as
+// "#tag", the text shown by the inline editor
+func editableText(todo Todo) string {
+	text := todo.Description
+	for _, tag := range todo.Tags {
+		text += " #" + tag
+	}
+	return text
+}
+
+// editSelectedTodo opens the inline editor of the last selected todo
+func (app *TodoApp) editSelectedTodo(isProject bool) {
+	tab := app.listTab(isProject)
+	if tab.selectedID != 0 {
+		app.startInlineEdit(tab.selectedID, tab.selectedIn, isProject)
+	}
+}
+
+// startInlineEdit shows the inline editor on the card of a todo
+func (app *TodoApp) startInlineEdit(todoID int, listType string, isProject bool) {
+	if listType == "trash" {
+		return
+	}
+	todo, ok := app.listFor(app.projectKey(isProject)).GetTodo(todoID)
+	if !ok {
+		return
+	}
+
+	tab := app.listTab(isProject)
+	tab.editingID = todoID
+	tab.editingIn = listType
+	tab.editText = editableText(todo)
+	tab.editFocusing = true
+	app.refreshAllLists()
+}
+
+// cancelInlineEdit closes the inline editor without saving
+func (app *TodoApp) cancelInlineEdit(isProject bool) {
+	tab := app.listTab(isProject)
+	tab.editingID = 0
+	tab.editingIn = ""
+	app.refreshAllLists()
+}
+
+// saveInlineEdit saves the text of the inline editor. Inline "#tags"
+// replace the tags of the todo.
+func (app *TodoApp) saveInlineEdit(isProject bool) {
+	tab := app.listTab(isProject)
+	if tab.editingID == 0 {
+		return
+	}
+
+	description, tags := ParseTags(tab.editText)
+	if tags == nil {
+		tags = []string{}
+	}
+	if !app.updateTodo(tab.editingID, TodoUpdate{Description: &description, Tags: &tags}, isProject) {
+		return
+	}
+	app.cancelInlineEdit(isProject)
+}
+
+// updateInlineEditItem renders the inline editor of a todo
+func (app *TodoApp) updateInlineEditItem(card *widget.Card, isProject bool) {
+	tab := app.listTab(isProject)
+
+	entry := newInlineEntry(func() {
+		app.cancelInlineEdit(isProject)
+	})
+	entry.SetText(tab.editText)
+	entry.OnChanged = func(text string) {
+		tab.editText = text
+	}
+	entry.OnSubmitted = func(string) {
+		app.saveInlineEdit(isProject)
+	}
+
+	saveBtn := widget.NewButton("💾", func() {
+		app.saveInlineEdit(isProject)
+	})
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("✖", func() {
+		app.cancelInlineEdit(isProject)
+	})
+
+	card.SetContent(container.NewPadded(container.NewBorder(
+		nil, nil, nil,
+		container.NewHBox(saveBtn, cancelBtn),
+		entry,
+	)))
+
+	if tab.editFocusing {
+		tab.editFocusing = false
+		app.window.Canvas().Focus(entry)
+	}
+}
+
+// updateTodo changes the fields of a todo and offers to undo it. It reports
+// whether the todo was updated.
+func (app *TodoApp) updateTodo(todoID int, update TodoUpdate, isProject bool) bool {
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	previous, _ := list.GetTodo(todoID)
+	if _, err := list.UpdateTodo(todoID, update); err != nil {
+		app.showListError(err)
+		return false
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Sửa: %s", previous.Description),
+		Undo: func() error {
+			return app.listFor(project).PutTodo(previous)
+		},
+		Redo: func() error {
+			_, err := app.listFor(project).UpdateTodo(todoID, update)
+			return err
+		},
+	})
+
+	app.refreshAllLists()
+	return true
+}
+
+// dragHandle is the "☰" handle of a card in the manually ordered list;
+// dragging it moves the todo up or down
+type dragHandle struct {
+	widget.Label
+	dragged   float32
+	onDragEnd func(dy float32)
+}
+
+// newDragHandle creates a drag handle that reports the vertical distance
+// it was dragged
+func newDragHandle(onDragEnd func(dy float32)) *dragHandle {
+	handle := &dragHandle{onDragEnd: onDragEnd}
+	handle.Text = "☰"
+	handle.ExtendBaseWidget(handle)
+	return handle
+}
+
+// Dragged adds up the vertical movement of the drag
+func (h *dragHandle) Dragged(event *fyne.DragEvent) {
+	h.dragged += event.Dragged.DY
+}
+
+// DragEnd reports the distance of the finished drag
+func (h *dragHandle) DragEnd() {
+	dy := h.dragged
+	h.dragged = 0
+	if h.onDragEnd != nil {
+		h.onDragEnd(dy)
+	}
+}
+
+// createDragHandle returns the drag handle of a card in the "Tất cả" list
+// sorted manually, or nil for other lists
+func (app *TodoApp) createDragHandle(row int, card *widget.Card, listType string, isProject bool) fyne.CanvasObject {
+	view := app.views(isProject)[listType]
+	if listType != "all" || view == nil || view.sortMode != SortManual {
+		return nil
+	}
+
+	return newDragHandle(func(dy float32) {
+		rowHeight := card.Size().Height + theme.Padding()
+		if rowHeight <= 0 {
+			return
+		}
+		rows := int(math.Round(float64(dy / rowHeight)))
+		if rows != 0 {
+			app.moveTodoRows(view, row, row+rows, isProject)
+		}
+	})
+}
+
+// siblingTarget returns the todo of the same parent nearest to targetRow,
+// walking from row towards targetRow without leaving the parent
+func siblingTarget(view *todoView, row, targetRow int) (Todo, bool) {
+	if row < 0 || row >= len(view.todos) || len(view.depths) != len(view.todos) {
+		return Todo{}, false
+	}
+	targetRow = max(0, min(targetRow, len(view.todos)-1))
+
+	step := 1
+	if targetRow < row {
+		step = -1
+	}
+	depth := view.depths[row]
+	var target Todo
+	found := false
+	for k := row + step; k != targetRow+step; k += step {
+		if view.depths[k] < depth {
+			break
+		}
+		if view.depths[k] == depth {
+			target, found = view.todos[k], true
+		}
+	}
+	return target, found
+}
+
+// moveTodoRows moves the todo shown at row towards targetRow, among the
+// todos of the same parent, and offers to undo it
+func (app *TodoApp) moveTodoRows(view *todoView, row, targetRow int, isProject bool) {
+	target, ok := siblingTarget(view, row, targetRow)
+	if !ok {
+		return
+	}
+	todo := view.todos[row]
+
+	project := app.projectKey(isProject)
+	list := app.listFor(project)
+
+	// Keep the positions of the siblings for undo
+	previous := list.Siblings(todo.ParentID)
+	if err := list.MoveTodo(todo.ID, target.ID); err != nil {
+		app.showListError(err)
+		return
+	}
+
+	app.undoStack.Push(Command{
+		Label: fmt.Sprintf("Di chuyển: %s", todo.Description),
+		Undo: func() error {
+			return putTodos(app.listFor(project), previous)
+		},
+		Redo: func() error {
+			return app.listFor(project).MoveTodo(todo.ID, target.ID)
+		},
+	})
+
+	// The selected row now shows another todo; the moved todo stays the
+	// target of the shortcuts through selectedID
+	view.list.UnselectAll()
+	app.refreshAllLists()
+}
+
+// moveSelectedTodo moves the selected todo of the "Tất cả" list one sibling
+// up (offset -1) or down (offset 1), switching the list to manual order
+func (app *TodoApp) moveSelectedTodo(offset int, isProject bool) {
+	tab := app.listTab(isProject)
+	view := tab.views["all"]
+	if view == nil || tab.selectedID == 0 {
+		return
+	}
+
+	if view.sortMode != SortManual && view.sortSelect != nil {
+		view.sortSelect.SetSelected(sortModeLabel(SortManual))
+	}
+
+	row := view.indexOf(tab.selectedID)
+	if row < 0 {
+		return
+	}
+	app.moveTodoRows(view, row, row+offset, isProject)
+}
+
+// indexOf returns the row showing a todo, or -1
+func (v *todoView) indexOf(id int) int {
+	for k, todo := range v.todos {
+		if todo.ID == id {
+			return k
+		}
+	}
+	return -1
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay is how long the watcher waits after the last change before
+// reloading, so that a burst of writes (temp file, rename, journal) causes a
+// single reload
+const watchDelay = 300 * time.Millisecond
+
+// fileStamp identifies a version of a file; a missing file is the zero stamp
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// listStamp identifies the versions of the data file and journal of a list
+type listStamp struct {
+	data, journal fileStamp
+}
+
+// statFile returns the stamp of a file
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}
+}
+
+// statFiles returns the current stamp of the data files of the list
+func (tl *TodoList) statFiles() listStamp {
+	return listStamp{
+		data:    statFile(tl.store.DataPath(tl.project)),
+		journal: statFile(tl.journal.path),
+	}
+}
+
+// ReloadIfChanged reloads the list if its data files changed since it last
+// read or wrote them, e.g. because they were edited by another program, and
+// reports whether it did. Edits of another program to the snapshot are
+// merged and written back with the journal compacted, so that they are not
+// undone by replaying the journal; todos changed on both sides keep the
+// version on disk.
+func (tl *TodoList) ReloadIfChanged() bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	// Changes waiting for merge conflicts to be resolved must not be lost
+	if tl.unsaved || tl.statFiles() == tl.stamp {
+		return false
+	}
+	if tl.snapshotEdited() {
+		err := tl.writeMerged(tl.saveSnapshot, func(MergeConflict) bool { return false })
+		if err == nil {
+			return true
+		}
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	tl.load()
+	return true
+}
+
+// snapshotEdited reports whether a program other than this app edited the
+// snapshot of the list, see editedOutside
+func (tl *TodoList) snapshotEdited() bool {
+	lock, err := lockFile(tl.journal.path)
+	if err != nil {
+		return false
+	}
+	defer lock.Unlock()
+
+	gen, err := lock.Generation()
+	if err != nil {
+		return false
+	}
+	snapshot, err := tl.store.LoadTodos(tl.project)
+	return err == nil && tl.editedOutside(gen, snapshot)
+}
+
+// dataWatcher watches the data directories of a store and calls onChange
+// once the files in them stop changing
+type dataWatcher struct {
+	watcher  *fsnotify.Watcher
+	onChange func()
+	timer    *time.Timer
+}
+
+// newDataWatcher starts watching the data directories of a store. Missing
+// directories are created so that files added later are seen.
+func newDataWatcher(store Store, onChange func()) (*dataWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range store.WatchDirs() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("không thể theo dõi thư mục %s: %v", dir, err)
+		}
+	}
+
+	w := &dataWatcher{watcher: watcher, onChange: onChange}
+	w.timer = time.AfterFunc(watchDelay, onChange)
+	w.timer.Stop()
+	go w.run()
+	return w, nil
+}
+
+// run waits for file events until the watcher is closed
+func (w *dataWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if isDataEvent(event) {
+				w.timer.Reset(watchDelay)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Printf("⚠️ Lỗi theo dõi file dữ liệu: %v\n", err)
+		}
+	}
+}
+
+// isDataEvent reports whether an event may change a list: lock files,
+// temporary files and backups are ignored
+func isDataEvent(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	return !strings.HasPrefix(name, ".") &&
+		!strings.HasSuffix(name, ".lock") &&
+		!strings.Contains(name, ".bak")
+}
+
+// Close stops watching
+func (w *dataWatcher) Close() error {
+	w.timer.Stop()
+	return w.watcher.Close()
+}
+
+// startWatching watches the data files of the current store, replacing the
+// watcher of a previous store
+func (app *TodoApp) startWatching() {
+	app.stopWatching()
+
+	watcher, err := newDataWatcher(app.store, func() {
+		fyne.Do(app.reloadChangedData)
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Không thể theo dõi thay đổi dữ liệu: %v\n", err)
+		return
+	}
+	app.watcher = watcher
+}
+
+// stopWatching stops the watcher of the data files, if any
+func (app *TodoApp) stopWatching() {
+	if app.watcher != nil {
+		app.watcher.Close()
+		app.watcher = nil
+	}
+}
+
+// reloadChangedData reloads the lists whose files were changed by another
+// program and updates the project dropdown when projects were added or
+// removed
+func (app *TodoApp) reloadChangedData() {
+	changed := app.todoList.ReloadIfChanged()
+	if changed {
+		fmt.Println("🔄 Reloaded todo list changed on disk")
+	}
+
+	if app.projectList != nil && app.projectList.ReloadIfChanged() {
+		changed = true
+		fmt.Printf("🔄 Reloaded project changed on disk: %s\n", app.currentProject)
+		app.reloadProjectMeta()
+	}
+
+	if app.syncProjectList() || changed {
+		app.refreshAllLists()
+	}
+}
+
+// reloadProjectMeta applies the metadata of the current project again,
+// e.g. after its color was edited in the file
+func (app *TodoApp) reloadProjectMeta() {
+	meta, err := app.store.LoadProject(app.currentProject)
+	if err != nil {
+		return
+	}
+	if meta.Color == app.projectList.GetColor() && meta.BackgroundImage == app.projectList.GetBackgroundImage() {
+		return
+	}
+
+	app.projectList.SetColor(meta.Color)
+	app.projectList.SetTheme(meta.Color)
+	app.projectList.SetBackgroundImage(meta.BackgroundImage)
+	app.projectColor = meta.Color
+	app.applyProjectTheme()
+}
+
+// syncProjectList updates the project dropdown when projects were added or
+// removed on disk and reports whether the current project was removed
+func (app *TodoApp) syncProjectList() bool {
+	projects, err := app.store.ListProjects()
+	if err != nil {
+		return false
+	}
+
+	names := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		names[project.Name] = true
+	}
+	shown := make(map[string]bool, len(app.projectSelect.Options))
+	for _, name := range app.projectSelect.Options {
+		if name != noProjectsOption {
+			shown[name] = true
+		}
+	}
+	if maps.Equal(names, shown) {
+		return false
+	}
+
+	removed := app.currentProject != "" && !names[app.currentProject]
+	if removed {
+		fmt.Printf("🔄 Project removed on disk: %s\n", app.currentProject)
+		app.clearCurrentProject()
+	}
+	app.refreshProjectList()
+	return removed
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReloadExternalEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func([]Todo) []Todo
+		want []Todo
+	}{
+		{"delete",
+			func(todos []Todo) []Todo {
+				return slices.DeleteFunc(todos, func(todo Todo) bool { return todo.ID == 1 })
+			},
+			[]Todo{{ID: 2, Description: "hai"}, {ID: 3, Description: "ba"}}},
+		{"edit",
+			func(todos []Todo) []Todo {
+				todos[1].Description = "hai đã sửa"
+				return todos
+			},
+			[]Todo{{ID: 1, Description: "một", Completed: true}, {ID: 2, Description: "hai đã sửa"}, {ID: 3, Description: "ba"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := NewTextStore(filepath.Join(dir, "todos.txt"), filepath.Join(dir, "project"))
+			list := NewTodoList(store, "")
+			for _, description := range []string{"một", "hai"} {
+				if err := list.AddTodo(description); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := list.Compact(); err != nil {
+				t.Fatal(err)
+			}
+			// Only in the journal
+			if err := list.MarkComplete(1); err != nil {
+				t.Fatal(err)
+			}
+			if err := list.AddTodo("ba"); err != nil {
+				t.Fatal(err)
+			}
+
+			// A script rewrites the snapshot, unaware of the journal
+			snapshot, err := store.LoadTodos("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SaveTodos("", test.edit(snapshot)); err != nil {
+				t.Fatal(err)
+			}
+
+			if !list.ReloadIfChanged() {
+				t.Fatal("list not reloaded")
+			}
+			for _, list := range []*TodoList{list, NewTodoList(store, "")} {
+				got := list.AllTodos()
+				if len(got) != len(test.want) {
+					t.Fatalf("todos = %+v, want %+v", got, test.want)
+				}
+				for i, todo := range got {
+					want := test.want[i]
+					if todo.ID != want.ID || todo.Description != want.Description || todo.Completed != want.Completed {
+						t.Errorf("todo %d = %+v, want %+v", i, todo, want)
+					}
+				}
+			}
+			if list.journal.Len() != 0 {
+				t.Errorf("journal of %d entries not compacted", list.journal.Len())
+			}
+		})
+	}
+}